@@ -32,6 +32,11 @@ const (
 	InputType_INPUT_SOFT_DROP   InputType = 5
 	InputType_INPUT_HARD_DROP   InputType = 6
 	InputType_INPUT_HOLD        InputType = 7
+	// Takes back the last placed piece. Only zen games accept it.
+	InputType_INPUT_UNDO InputType = 8
+	// Pauses the match, or resumes it if it is paused. Matches with several
+	// players only pause once every player has asked; anyone can resume.
+	InputType_INPUT_PAUSE InputType = 9
 )
 
 // Enum value maps for InputType.
@@ -45,6 +50,8 @@ var (
 		5: "INPUT_SOFT_DROP",
 		6: "INPUT_HARD_DROP",
 		7: "INPUT_HOLD",
+		8: "INPUT_UNDO",
+		9: "INPUT_PAUSE",
 	}
 	InputType_value = map[string]int32{
 		"INPUT_UNSPECIFIED": 0,
@@ -55,6 +62,8 @@ var (
 		"INPUT_SOFT_DROP":   5,
 		"INPUT_HARD_DROP":   6,
 		"INPUT_HOLD":        7,
+		"INPUT_UNDO":        8,
+		"INPUT_PAUSE":       9,
 	}
 )
 
@@ -160,16 +169,40 @@ const (
 	EventType_EVENT_GAME_OVER        EventType = 2
 	EventType_EVENT_WINNER           EventType = 3
 	EventType_EVENT_GARBAGE_RECEIVED EventType = 4
+	EventType_EVENT_COMBO            EventType = 5
+	EventType_EVENT_BACK_TO_BACK     EventType = 6
+	EventType_EVENT_PERFECT_CLEAR    EventType = 7
+	// The player reached the goal of the mode. Metadata carries mode, frames,
+	// time_ms, pieces, pps, lines and score.
+	EventType_EVENT_RESULT EventType = 8
+	// Metadata of the pause events carries by_id and by_name, the player who
+	// asked.
+	EventType_EVENT_PAUSED  EventType = 9
+	EventType_EVENT_RESUMED EventType = 10
+	// A player asked to pause a multiplayer match. It pauses once every
+	// player has sent INPUT_PAUSE within ten seconds of each other.
+	EventType_EVENT_PAUSE_REQUESTED EventType = 11
+	// A soft or hard drop input moved the piece. Metadata carries kind (soft
+	// or hard), cells and points.
+	EventType_EVENT_DROP EventType = 12
 )
 
 // Enum value maps for EventType.
 var (
 	EventType_name = map[int32]string{
-		0: "EVENT_UNSPECIFIED",
-		1: "EVENT_MATCH_START",
-		2: "EVENT_GAME_OVER",
-		3: "EVENT_WINNER",
-		4: "EVENT_GARBAGE_RECEIVED",
+		0:  "EVENT_UNSPECIFIED",
+		1:  "EVENT_MATCH_START",
+		2:  "EVENT_GAME_OVER",
+		3:  "EVENT_WINNER",
+		4:  "EVENT_GARBAGE_RECEIVED",
+		5:  "EVENT_COMBO",
+		6:  "EVENT_BACK_TO_BACK",
+		7:  "EVENT_PERFECT_CLEAR",
+		8:  "EVENT_RESULT",
+		9:  "EVENT_PAUSED",
+		10: "EVENT_RESUMED",
+		11: "EVENT_PAUSE_REQUESTED",
+		12: "EVENT_DROP",
 	}
 	EventType_value = map[string]int32{
 		"EVENT_UNSPECIFIED":      0,
@@ -177,6 +210,14 @@ var (
 		"EVENT_GAME_OVER":        2,
 		"EVENT_WINNER":           3,
 		"EVENT_GARBAGE_RECEIVED": 4,
+		"EVENT_COMBO":            5,
+		"EVENT_BACK_TO_BACK":     6,
+		"EVENT_PERFECT_CLEAR":    7,
+		"EVENT_RESULT":           8,
+		"EVENT_PAUSED":           9,
+		"EVENT_RESUMED":          10,
+		"EVENT_PAUSE_REQUESTED":  11,
+		"EVENT_DROP":             12,
 	}
 )
 
@@ -214,6 +255,7 @@ type ClientMessage struct {
 	//	*ClientMessage_Join
 	//	*ClientMessage_Input
 	//	*ClientMessage_Ping
+	//	*ClientMessage_Resync
 	Payload       isClientMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -283,6 +325,15 @@ func (x *ClientMessage) GetPing() *PingRequest {
 	return nil
 }
 
+func (x *ClientMessage) GetResync() *ResyncRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientMessage_Resync); ok {
+			return x.Resync
+		}
+	}
+	return nil
+}
+
 type isClientMessage_Payload interface {
 	isClientMessage_Payload()
 }
@@ -299,16 +350,37 @@ type ClientMessage_Ping struct {
 	Ping *PingRequest `protobuf:"bytes,3,opt,name=ping,proto3,oneof"`
 }
 
+type ClientMessage_Resync struct {
+	Resync *ResyncRequest `protobuf:"bytes,4,opt,name=resync,proto3,oneof"`
+}
+
 func (*ClientMessage_Join) isClientMessage_Payload() {}
 
 func (*ClientMessage_Input) isClientMessage_Payload() {}
 
 func (*ClientMessage_Ping) isClientMessage_Payload() {}
 
+func (*ClientMessage_Resync) isClientMessage_Payload() {}
+
 type JoinRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MatchId       string                 `protobuf:"bytes,1,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
-	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	MatchId     string                 `protobuf:"bytes,1,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	Token       string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Randomizer  string                 `protobuf:"bytes,3,opt,name=randomizer,proto3" json:"randomizer,omitempty"`
+	ResumeToken string                 `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// Display name for players whose token does not carry one.
+	Name string `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	// Game mode: "marathon" (default), "sprint", "ultra", "dig" or "zen".
+	// Modes other than marathon are single player.
+	Mode string `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"`
+	// Lines to clear in sprint mode: 20, 40 or 100. Zero uses the server
+	// default.
+	SprintLines int32 `protobuf:"varint,7,opt,name=sprint_lines,json=sprintLines,proto3" json:"sprint_lines,omitempty"`
+	// Garbage lines to clear in dig mode: 10, 18 or 100. Zero uses the server
+	// default.
+	DigLines int32 `protobuf:"varint,8,opt,name=dig_lines,json=digLines,proto3" json:"dig_lines,omitempty"`
+	// Keep the dig garbage hole in one column instead of moving it every row.
+	CleanCheese   bool `protobuf:"varint,9,opt,name=clean_cheese,json=cleanCheese,proto3" json:"clean_cheese,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -357,6 +429,55 @@ func (x *JoinRequest) GetToken() string {
 	return ""
 }
 
+func (x *JoinRequest) GetRandomizer() string {
+	if x != nil {
+		return x.Randomizer
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *JoinRequest) GetSprintLines() int32 {
+	if x != nil {
+		return x.SprintLines
+	}
+	return 0
+}
+
+func (x *JoinRequest) GetDigLines() int32 {
+	if x != nil {
+		return x.DigLines
+	}
+	return 0
+}
+
+func (x *JoinRequest) GetCleanCheese() bool {
+	if x != nil {
+		return x.CleanCheese
+	}
+	return false
+}
+
 type InputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SequenceId    uint64                 `protobuf:"varint,1,opt,name=sequence_id,json=sequenceId,proto3" json:"sequence_id,omitempty"`
@@ -409,16 +530,105 @@ func (x *InputRequest) GetInput() InputType {
 	return InputType_INPUT_UNSPECIFIED
 }
 
-type PingRequest struct {
+type SpectateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MatchId       string                 `protobuf:"bytes,1,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpectateRequest) Reset() {
+	*x = SpectateRequest{}
+	mi := &file_game_v1_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpectateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpectateRequest) ProtoMessage() {}
+
+func (x *SpectateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpectateRequest.ProtoReflect.Descriptor instead.
+func (*SpectateRequest) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SpectateRequest) GetMatchId() string {
+	if x != nil {
+		return x.MatchId
+	}
+	return ""
+}
+
+// ResyncRequest asks the server for a full StateUpdate, for clients that
+// cannot apply a StateDelta to what they have.
+type ResyncRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *ResyncRequest) Reset() {
+	*x = ResyncRequest{}
+	mi := &file_game_v1_game_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResyncRequest) ProtoMessage() {}
+
+func (x *ResyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResyncRequest.ProtoReflect.Descriptor instead.
+func (*ResyncRequest) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{4}
+}
+
+// PingRequest measures latency in both directions. timestamp is read from
+// the client's clock and echoed back in the PongResponse. server_timestamp
+// returns the one from the previous pong, and echo_delay says how many
+// nanoseconds the client held it, so the server can measure the round trip
+// too.
+type PingRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp       int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ServerTimestamp int64                  `protobuf:"varint,2,opt,name=server_timestamp,json=serverTimestamp,proto3" json:"server_timestamp,omitempty"`
+	EchoDelay       int64                  `protobuf:"varint,3,opt,name=echo_delay,json=echoDelay,proto3" json:"echo_delay,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
 func (x *PingRequest) Reset() {
 	*x = PingRequest{}
-	mi := &file_game_v1_game_proto_msgTypes[3]
+	mi := &file_game_v1_game_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -430,7 +640,7 @@ func (x *PingRequest) String() string {
 func (*PingRequest) ProtoMessage() {}
 
 func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[3]
+	mi := &file_game_v1_game_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -443,7 +653,7 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
 func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{3}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *PingRequest) GetTimestamp() int64 {
@@ -453,6 +663,20 @@ func (x *PingRequest) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *PingRequest) GetServerTimestamp() int64 {
+	if x != nil {
+		return x.ServerTimestamp
+	}
+	return 0
+}
+
+func (x *PingRequest) GetEchoDelay() int64 {
+	if x != nil {
+		return x.EchoDelay
+	}
+	return 0
+}
+
 type ServerMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Payload:
@@ -460,6 +684,8 @@ type ServerMessage struct {
 	//	*ServerMessage_State
 	//	*ServerMessage_Event
 	//	*ServerMessage_Pong
+	//	*ServerMessage_Session
+	//	*ServerMessage_Delta
 	Payload       isServerMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -467,7 +693,7 @@ type ServerMessage struct {
 
 func (x *ServerMessage) Reset() {
 	*x = ServerMessage{}
-	mi := &file_game_v1_game_proto_msgTypes[4]
+	mi := &file_game_v1_game_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -479,7 +705,7 @@ func (x *ServerMessage) String() string {
 func (*ServerMessage) ProtoMessage() {}
 
 func (x *ServerMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[4]
+	mi := &file_game_v1_game_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -492,7 +718,7 @@ func (x *ServerMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServerMessage.ProtoReflect.Descriptor instead.
 func (*ServerMessage) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{4}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ServerMessage) GetPayload() isServerMessage_Payload {
@@ -529,6 +755,24 @@ func (x *ServerMessage) GetPong() *PongResponse {
 	return nil
 }
 
+func (x *ServerMessage) GetSession() *SessionInfo {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Session); ok {
+			return x.Session
+		}
+	}
+	return nil
+}
+
+func (x *ServerMessage) GetDelta() *StateDelta {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_Delta); ok {
+			return x.Delta
+		}
+	}
+	return nil
+}
+
 type isServerMessage_Payload interface {
 	isServerMessage_Payload()
 }
@@ -545,28 +789,115 @@ type ServerMessage_Pong struct {
 	Pong *PongResponse `protobuf:"bytes,3,opt,name=pong,proto3,oneof"`
 }
 
+type ServerMessage_Session struct {
+	Session *SessionInfo `protobuf:"bytes,4,opt,name=session,proto3,oneof"`
+}
+
+type ServerMessage_Delta struct {
+	Delta *StateDelta `protobuf:"bytes,5,opt,name=delta,proto3,oneof"`
+}
+
 func (*ServerMessage_State) isServerMessage_Payload() {}
 
 func (*ServerMessage_Event) isServerMessage_Payload() {}
 
 func (*ServerMessage_Pong) isServerMessage_Payload() {}
 
-type StateUpdate struct {
+func (*ServerMessage_Session) isServerMessage_Payload() {}
+
+func (*ServerMessage_Delta) isServerMessage_Payload() {}
+
+type SessionInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TickId        uint64                 `protobuf:"varint,1,opt,name=tick_id,json=tickId,proto3" json:"tick_id,omitempty"`
-	Grid          []byte                 `protobuf:"bytes,2,opt,name=grid,proto3" json:"grid,omitempty"`
-	CurrentPiece  *Piece                 `protobuf:"bytes,3,opt,name=current_piece,json=currentPiece,proto3" json:"current_piece,omitempty"`
-	NextPieces    []PieceType            `protobuf:"varint,4,rep,packed,name=next_pieces,json=nextPieces,proto3,enum=game.v1.PieceType" json:"next_pieces,omitempty"`
-	HeldPiece     PieceType              `protobuf:"varint,5,opt,name=held_piece,json=heldPiece,proto3,enum=game.v1.PieceType" json:"held_piece,omitempty"`
-	Score         int32                  `protobuf:"varint,6,opt,name=score,proto3" json:"score,omitempty"`
-	Level         int32                  `protobuf:"varint,7,opt,name=level,proto3" json:"level,omitempty"`
+	ResumeToken   string                 `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	PlayerId      string                 `protobuf:"bytes,2,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	MatchId       string                 `protobuf:"bytes,3,opt,name=match_id,json=matchId,proto3" json:"match_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	mi := &file_game_v1_game_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionInfo) ProtoMessage() {}
+
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SessionInfo) GetResumeToken() string {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetMatchId() string {
+	if x != nil {
+		return x.MatchId
+	}
+	return ""
+}
+
+type StateUpdate struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TickId         uint64                 `protobuf:"varint,1,opt,name=tick_id,json=tickId,proto3" json:"tick_id,omitempty"`
+	Grid           []byte                 `protobuf:"bytes,2,opt,name=grid,proto3" json:"grid,omitempty"`
+	CurrentPiece   *Piece                 `protobuf:"bytes,3,opt,name=current_piece,json=currentPiece,proto3" json:"current_piece,omitempty"`
+	NextPieces     []PieceType            `protobuf:"varint,4,rep,packed,name=next_pieces,json=nextPieces,proto3,enum=game.v1.PieceType" json:"next_pieces,omitempty"`
+	HeldPiece      PieceType              `protobuf:"varint,5,opt,name=held_piece,json=heldPiece,proto3,enum=game.v1.PieceType" json:"held_piece,omitempty"`
+	Score          int32                  `protobuf:"varint,6,opt,name=score,proto3" json:"score,omitempty"`
+	Level          int32                  `protobuf:"varint,7,opt,name=level,proto3" json:"level,omitempty"`
+	PendingGarbage int32                  `protobuf:"varint,8,opt,name=pending_garbage,json=pendingGarbage,proto3" json:"pending_garbage,omitempty"`
+	PlayerId       string                 `protobuf:"bytes,9,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	// Sequence id of the last input the server applied, for client-side
+	// reconciliation.
+	LastSequenceId uint64 `protobuf:"varint,10,opt,name=last_sequence_id,json=lastSequenceId,proto3" json:"last_sequence_id,omitempty"`
+	Mode           string `protobuf:"bytes,11,opt,name=mode,proto3" json:"mode,omitempty"`
+	// Lines cleared. Dig mode only counts garbage lines.
+	Lines int32 `protobuf:"varint,12,opt,name=lines,proto3" json:"lines,omitempty"`
+	// Lines that end the game, zero if the mode has no line goal.
+	LineGoal int32 `protobuf:"varint,13,opt,name=line_goal,json=lineGoal,proto3" json:"line_goal,omitempty"`
+	// Milliseconds left before a timed mode ends, zero if the mode has no time
+	// limit.
+	TimeRemainingMs int64 `protobuf:"varint,14,opt,name=time_remaining_ms,json=timeRemainingMs,proto3" json:"time_remaining_ms,omitempty"`
+	// The game is paused. The grid and pieces are blank until it resumes.
+	Paused        bool `protobuf:"varint,15,opt,name=paused,proto3" json:"paused,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StateUpdate) Reset() {
 	*x = StateUpdate{}
-	mi := &file_game_v1_game_proto_msgTypes[5]
+	mi := &file_game_v1_game_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -578,7 +909,7 @@ func (x *StateUpdate) String() string {
 func (*StateUpdate) ProtoMessage() {}
 
 func (x *StateUpdate) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[5]
+	mi := &file_game_v1_game_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -591,7 +922,7 @@ func (x *StateUpdate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StateUpdate.ProtoReflect.Descriptor instead.
 func (*StateUpdate) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{5}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *StateUpdate) GetTickId() uint64 {
@@ -643,6 +974,301 @@ func (x *StateUpdate) GetLevel() int32 {
 	return 0
 }
 
+func (x *StateUpdate) GetPendingGarbage() int32 {
+	if x != nil {
+		return x.PendingGarbage
+	}
+	return 0
+}
+
+func (x *StateUpdate) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *StateUpdate) GetLastSequenceId() uint64 {
+	if x != nil {
+		return x.LastSequenceId
+	}
+	return 0
+}
+
+func (x *StateUpdate) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *StateUpdate) GetLines() int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *StateUpdate) GetLineGoal() int32 {
+	if x != nil {
+		return x.LineGoal
+	}
+	return 0
+}
+
+func (x *StateUpdate) GetTimeRemainingMs() int64 {
+	if x != nil {
+		return x.TimeRemainingMs
+	}
+	return 0
+}
+
+func (x *StateUpdate) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+// StateDelta carries only what changed since the previous state message on
+// the stream. Unset fields are unchanged. A full StateUpdate is sent as a
+// keyframe periodically and on request.
+type StateDelta struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TickId          uint64                 `protobuf:"varint,1,opt,name=tick_id,json=tickId,proto3" json:"tick_id,omitempty"`
+	LastSequenceId  uint64                 `protobuf:"varint,2,opt,name=last_sequence_id,json=lastSequenceId,proto3" json:"last_sequence_id,omitempty"`
+	PlayerId        string                 `protobuf:"bytes,3,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	Cells           []*CellChange          `protobuf:"bytes,4,rep,name=cells,proto3" json:"cells,omitempty"`
+	CurrentPiece    *Piece                 `protobuf:"bytes,5,opt,name=current_piece,json=currentPiece,proto3" json:"current_piece,omitempty"`
+	NextPieces      *PieceQueue            `protobuf:"bytes,6,opt,name=next_pieces,json=nextPieces,proto3" json:"next_pieces,omitempty"`
+	HeldPiece       *PieceType             `protobuf:"varint,7,opt,name=held_piece,json=heldPiece,proto3,enum=game.v1.PieceType,oneof" json:"held_piece,omitempty"`
+	Score           *int32                 `protobuf:"varint,8,opt,name=score,proto3,oneof" json:"score,omitempty"`
+	Level           *int32                 `protobuf:"varint,9,opt,name=level,proto3,oneof" json:"level,omitempty"`
+	PendingGarbage  *int32                 `protobuf:"varint,10,opt,name=pending_garbage,json=pendingGarbage,proto3,oneof" json:"pending_garbage,omitempty"`
+	Lines           *int32                 `protobuf:"varint,11,opt,name=lines,proto3,oneof" json:"lines,omitempty"`
+	TimeRemainingMs *int64                 `protobuf:"varint,12,opt,name=time_remaining_ms,json=timeRemainingMs,proto3,oneof" json:"time_remaining_ms,omitempty"`
+	Paused          *bool                  `protobuf:"varint,13,opt,name=paused,proto3,oneof" json:"paused,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StateDelta) Reset() {
+	*x = StateDelta{}
+	mi := &file_game_v1_game_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateDelta) ProtoMessage() {}
+
+func (x *StateDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateDelta.ProtoReflect.Descriptor instead.
+func (*StateDelta) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StateDelta) GetTickId() uint64 {
+	if x != nil {
+		return x.TickId
+	}
+	return 0
+}
+
+func (x *StateDelta) GetLastSequenceId() uint64 {
+	if x != nil {
+		return x.LastSequenceId
+	}
+	return 0
+}
+
+func (x *StateDelta) GetPlayerId() string {
+	if x != nil {
+		return x.PlayerId
+	}
+	return ""
+}
+
+func (x *StateDelta) GetCells() []*CellChange {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+func (x *StateDelta) GetCurrentPiece() *Piece {
+	if x != nil {
+		return x.CurrentPiece
+	}
+	return nil
+}
+
+func (x *StateDelta) GetNextPieces() *PieceQueue {
+	if x != nil {
+		return x.NextPieces
+	}
+	return nil
+}
+
+func (x *StateDelta) GetHeldPiece() PieceType {
+	if x != nil && x.HeldPiece != nil {
+		return *x.HeldPiece
+	}
+	return PieceType_PIECE_UNSPECIFIED
+}
+
+func (x *StateDelta) GetScore() int32 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+func (x *StateDelta) GetLevel() int32 {
+	if x != nil && x.Level != nil {
+		return *x.Level
+	}
+	return 0
+}
+
+func (x *StateDelta) GetPendingGarbage() int32 {
+	if x != nil && x.PendingGarbage != nil {
+		return *x.PendingGarbage
+	}
+	return 0
+}
+
+func (x *StateDelta) GetLines() int32 {
+	if x != nil && x.Lines != nil {
+		return *x.Lines
+	}
+	return 0
+}
+
+func (x *StateDelta) GetTimeRemainingMs() int64 {
+	if x != nil && x.TimeRemainingMs != nil {
+		return *x.TimeRemainingMs
+	}
+	return 0
+}
+
+func (x *StateDelta) GetPaused() bool {
+	if x != nil && x.Paused != nil {
+		return *x.Paused
+	}
+	return false
+}
+
+type CellChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Type          PieceType              `protobuf:"varint,2,opt,name=type,proto3,enum=game.v1.PieceType" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CellChange) Reset() {
+	*x = CellChange{}
+	mi := &file_game_v1_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CellChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CellChange) ProtoMessage() {}
+
+func (x *CellChange) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CellChange.ProtoReflect.Descriptor instead.
+func (*CellChange) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CellChange) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CellChange) GetType() PieceType {
+	if x != nil {
+		return x.Type
+	}
+	return PieceType_PIECE_UNSPECIFIED
+}
+
+type PieceQueue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pieces        []PieceType            `protobuf:"varint,1,rep,packed,name=pieces,proto3,enum=game.v1.PieceType" json:"pieces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PieceQueue) Reset() {
+	*x = PieceQueue{}
+	mi := &file_game_v1_game_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PieceQueue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PieceQueue) ProtoMessage() {}
+
+func (x *PieceQueue) ProtoReflect() protoreflect.Message {
+	mi := &file_game_v1_game_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PieceQueue.ProtoReflect.Descriptor instead.
+func (*PieceQueue) Descriptor() ([]byte, []int) {
+	return file_game_v1_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PieceQueue) GetPieces() []PieceType {
+	if x != nil {
+		return x.Pieces
+	}
+	return nil
+}
+
 type GameEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=game.v1.EventType" json:"type,omitempty"`
@@ -654,7 +1280,7 @@ type GameEvent struct {
 
 func (x *GameEvent) Reset() {
 	*x = GameEvent{}
-	mi := &file_game_v1_game_proto_msgTypes[6]
+	mi := &file_game_v1_game_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -666,7 +1292,7 @@ func (x *GameEvent) String() string {
 func (*GameEvent) ProtoMessage() {}
 
 func (x *GameEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[6]
+	mi := &file_game_v1_game_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -679,7 +1305,7 @@ func (x *GameEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GameEvent.ProtoReflect.Descriptor instead.
 func (*GameEvent) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{6}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *GameEvent) GetType() EventType {
@@ -704,15 +1330,17 @@ func (x *GameEvent) GetMetadata() map[string]string {
 }
 
 type PongResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Server clock in unix nanoseconds when the pong was sent.
+	ServerTimestamp int64 `protobuf:"varint,2,opt,name=server_timestamp,json=serverTimestamp,proto3" json:"server_timestamp,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *PongResponse) Reset() {
 	*x = PongResponse{}
-	mi := &file_game_v1_game_proto_msgTypes[7]
+	mi := &file_game_v1_game_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -724,7 +1352,7 @@ func (x *PongResponse) String() string {
 func (*PongResponse) ProtoMessage() {}
 
 func (x *PongResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[7]
+	mi := &file_game_v1_game_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -737,7 +1365,7 @@ func (x *PongResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PongResponse.ProtoReflect.Descriptor instead.
 func (*PongResponse) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{7}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *PongResponse) GetTimestamp() int64 {
@@ -747,6 +1375,13 @@ func (x *PongResponse) GetTimestamp() int64 {
 	return 0
 }
 
+func (x *PongResponse) GetServerTimestamp() int64 {
+	if x != nil {
+		return x.ServerTimestamp
+	}
+	return 0
+}
+
 type Piece struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          PieceType              `protobuf:"varint,1,opt,name=type,proto3,enum=game.v1.PieceType" json:"type,omitempty"`
@@ -759,7 +1394,7 @@ type Piece struct {
 
 func (x *Piece) Reset() {
 	*x = Piece{}
-	mi := &file_game_v1_game_proto_msgTypes[8]
+	mi := &file_game_v1_game_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -771,7 +1406,7 @@ func (x *Piece) String() string {
 func (*Piece) ProtoMessage() {}
 
 func (x *Piece) ProtoReflect() protoreflect.Message {
-	mi := &file_game_v1_game_proto_msgTypes[8]
+	mi := &file_game_v1_game_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -784,7 +1419,7 @@ func (x *Piece) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Piece.ProtoReflect.Descriptor instead.
 func (*Piece) Descriptor() ([]byte, []int) {
-	return file_game_v1_game_proto_rawDescGZIP(), []int{8}
+	return file_game_v1_game_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Piece) GetType() PieceType {
@@ -819,26 +1454,48 @@ var File_game_v1_game_proto protoreflect.FileDescriptor
 
 const file_game_v1_game_proto_rawDesc = "" +
 	"\n" +
-	"\x12game/v1/game.proto\x12\agame.v1\"\xa1\x01\n" +
+	"\x12game/v1/game.proto\x12\agame.v1\"\xd3\x01\n" +
 	"\rClientMessage\x12*\n" +
 	"\x04join\x18\x01 \x01(\v2\x14.game.v1.JoinRequestH\x00R\x04join\x12-\n" +
 	"\x05input\x18\x02 \x01(\v2\x15.game.v1.InputRequestH\x00R\x05input\x12*\n" +
-	"\x04ping\x18\x03 \x01(\v2\x14.game.v1.PingRequestH\x00R\x04pingB\t\n" +
-	"\apayload\">\n" +
+	"\x04ping\x18\x03 \x01(\v2\x14.game.v1.PingRequestH\x00R\x04ping\x120\n" +
+	"\x06resync\x18\x04 \x01(\v2\x16.game.v1.ResyncRequestH\x00R\x06resyncB\t\n" +
+	"\apayload\"\x8c\x02\n" +
 	"\vJoinRequest\x12\x19\n" +
 	"\bmatch_id\x18\x01 \x01(\tR\amatchId\x12\x14\n" +
-	"\x05token\x18\x02 \x01(\tR\x05token\"Y\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1e\n" +
+	"\n" +
+	"randomizer\x18\x03 \x01(\tR\n" +
+	"randomizer\x12!\n" +
+	"\fresume_token\x18\x04 \x01(\tR\vresumeToken\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\x12!\n" +
+	"\fsprint_lines\x18\a \x01(\x05R\vsprintLines\x12\x1b\n" +
+	"\tdig_lines\x18\b \x01(\x05R\bdigLines\x12!\n" +
+	"\fclean_cheese\x18\t \x01(\bR\vcleanCheese\"Y\n" +
 	"\fInputRequest\x12\x1f\n" +
 	"\vsequence_id\x18\x01 \x01(\x04R\n" +
 	"sequenceId\x12(\n" +
-	"\x05input\x18\x02 \x01(\x0e2\x12.game.v1.InputTypeR\x05input\"+\n" +
+	"\x05input\x18\x02 \x01(\x0e2\x12.game.v1.InputTypeR\x05input\",\n" +
+	"\x0fSpectateRequest\x12\x19\n" +
+	"\bmatch_id\x18\x01 \x01(\tR\amatchId\"\x0f\n" +
+	"\rResyncRequest\"u\n" +
 	"\vPingRequest\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"\xa1\x01\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12)\n" +
+	"\x10server_timestamp\x18\x02 \x01(\x03R\x0fserverTimestamp\x12\x1d\n" +
+	"\n" +
+	"echo_delay\x18\x03 \x01(\x03R\techoDelay\"\x80\x02\n" +
 	"\rServerMessage\x12,\n" +
 	"\x05state\x18\x01 \x01(\v2\x14.game.v1.StateUpdateH\x00R\x05state\x12*\n" +
 	"\x05event\x18\x02 \x01(\v2\x12.game.v1.GameEventH\x00R\x05event\x12+\n" +
-	"\x04pong\x18\x03 \x01(\v2\x15.game.v1.PongResponseH\x00R\x04pongB\t\n" +
-	"\apayload\"\x83\x02\n" +
+	"\x04pong\x18\x03 \x01(\v2\x15.game.v1.PongResponseH\x00R\x04pong\x120\n" +
+	"\asession\x18\x04 \x01(\v2\x14.game.v1.SessionInfoH\x00R\asession\x12+\n" +
+	"\x05delta\x18\x05 \x01(\v2\x13.game.v1.StateDeltaH\x00R\x05deltaB\t\n" +
+	"\apayload\"h\n" +
+	"\vSessionInfo\x12!\n" +
+	"\fresume_token\x18\x01 \x01(\tR\vresumeToken\x12\x1b\n" +
+	"\tplayer_id\x18\x02 \x01(\tR\bplayerId\x12\x19\n" +
+	"\bmatch_id\x18\x03 \x01(\tR\amatchId\"\xfe\x03\n" +
 	"\vStateUpdate\x12\x17\n" +
 	"\atick_id\x18\x01 \x01(\x04R\x06tickId\x12\x12\n" +
 	"\x04grid\x18\x02 \x01(\fR\x04grid\x123\n" +
@@ -848,21 +1505,63 @@ const file_game_v1_game_proto_rawDesc = "" +
 	"\n" +
 	"held_piece\x18\x05 \x01(\x0e2\x12.game.v1.PieceTypeR\theldPiece\x12\x14\n" +
 	"\x05score\x18\x06 \x01(\x05R\x05score\x12\x14\n" +
-	"\x05level\x18\a \x01(\x05R\x05level\"\xc8\x01\n" +
+	"\x05level\x18\a \x01(\x05R\x05level\x12'\n" +
+	"\x0fpending_garbage\x18\b \x01(\x05R\x0ependingGarbage\x12\x1b\n" +
+	"\tplayer_id\x18\t \x01(\tR\bplayerId\x12(\n" +
+	"\x10last_sequence_id\x18\n" +
+	" \x01(\x04R\x0elastSequenceId\x12\x12\n" +
+	"\x04mode\x18\v \x01(\tR\x04mode\x12\x14\n" +
+	"\x05lines\x18\f \x01(\x05R\x05lines\x12\x1b\n" +
+	"\tline_goal\x18\r \x01(\x05R\blineGoal\x12*\n" +
+	"\x11time_remaining_ms\x18\x0e \x01(\x03R\x0ftimeRemainingMs\x12\x16\n" +
+	"\x06paused\x18\x0f \x01(\bR\x06paused\"\xe9\x04\n" +
+	"\n" +
+	"StateDelta\x12\x17\n" +
+	"\atick_id\x18\x01 \x01(\x04R\x06tickId\x12(\n" +
+	"\x10last_sequence_id\x18\x02 \x01(\x04R\x0elastSequenceId\x12\x1b\n" +
+	"\tplayer_id\x18\x03 \x01(\tR\bplayerId\x12)\n" +
+	"\x05cells\x18\x04 \x03(\v2\x13.game.v1.CellChangeR\x05cells\x123\n" +
+	"\rcurrent_piece\x18\x05 \x01(\v2\x0e.game.v1.PieceR\fcurrentPiece\x124\n" +
+	"\vnext_pieces\x18\x06 \x01(\v2\x13.game.v1.PieceQueueR\n" +
+	"nextPieces\x126\n" +
+	"\n" +
+	"held_piece\x18\a \x01(\x0e2\x12.game.v1.PieceTypeH\x00R\theldPiece\x88\x01\x01\x12\x19\n" +
+	"\x05score\x18\b \x01(\x05H\x01R\x05score\x88\x01\x01\x12\x19\n" +
+	"\x05level\x18\t \x01(\x05H\x02R\x05level\x88\x01\x01\x12,\n" +
+	"\x0fpending_garbage\x18\n" +
+	" \x01(\x05H\x03R\x0ependingGarbage\x88\x01\x01\x12\x19\n" +
+	"\x05lines\x18\v \x01(\x05H\x04R\x05lines\x88\x01\x01\x12/\n" +
+	"\x11time_remaining_ms\x18\f \x01(\x03H\x05R\x0ftimeRemainingMs\x88\x01\x01\x12\x1b\n" +
+	"\x06paused\x18\r \x01(\bH\x06R\x06paused\x88\x01\x01B\r\n" +
+	"\v_held_pieceB\b\n" +
+	"\x06_scoreB\b\n" +
+	"\x06_levelB\x12\n" +
+	"\x10_pending_garbageB\b\n" +
+	"\x06_linesB\x14\n" +
+	"\x12_time_remaining_msB\t\n" +
+	"\a_paused\"J\n" +
+	"\n" +
+	"CellChange\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12&\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x12.game.v1.PieceTypeR\x04type\"8\n" +
+	"\n" +
+	"PieceQueue\x12*\n" +
+	"\x06pieces\x18\x01 \x03(\x0e2\x12.game.v1.PieceTypeR\x06pieces\"\xc8\x01\n" +
 	"\tGameEvent\x12&\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x12.game.v1.EventTypeR\x04type\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12<\n" +
 	"\bmetadata\x18\x03 \x03(\v2 .game.v1.GameEvent.MetadataEntryR\bmetadata\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\",\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"W\n" +
 	"\fPongResponse\x12\x1c\n" +
-	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"g\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12)\n" +
+	"\x10server_timestamp\x18\x02 \x01(\x03R\x0fserverTimestamp\"g\n" +
 	"\x05Piece\x12&\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x12.game.v1.PieceTypeR\x04type\x12\f\n" +
 	"\x01x\x18\x02 \x01(\x05R\x01x\x12\f\n" +
 	"\x01y\x18\x03 \x01(\x05R\x01y\x12\x1a\n" +
-	"\brotation\x18\x04 \x01(\x05R\brotation*\xa8\x01\n" +
+	"\brotation\x18\x04 \x01(\x05R\brotation*\xc9\x01\n" +
 	"\tInputType\x12\x15\n" +
 	"\x11INPUT_UNSPECIFIED\x10\x00\x12\x0e\n" +
 	"\n" +
@@ -873,7 +1572,10 @@ const file_game_v1_game_proto_rawDesc = "" +
 	"\x0fINPUT_SOFT_DROP\x10\x05\x12\x13\n" +
 	"\x0fINPUT_HARD_DROP\x10\x06\x12\x0e\n" +
 	"\n" +
-	"INPUT_HOLD\x10\a*\x90\x01\n" +
+	"INPUT_HOLD\x10\a\x12\x0e\n" +
+	"\n" +
+	"INPUT_UNDO\x10\b\x12\x0f\n" +
+	"\vINPUT_PAUSE\x10\t*\x90\x01\n" +
 	"\tPieceType\x12\x15\n" +
 	"\x11PIECE_UNSPECIFIED\x10\x00\x12\v\n" +
 	"\aPIECE_I\x10\x01\x12\v\n" +
@@ -883,15 +1585,26 @@ const file_game_v1_game_proto_rawDesc = "" +
 	"\aPIECE_Z\x10\x05\x12\v\n" +
 	"\aPIECE_J\x10\x06\x12\v\n" +
 	"\aPIECE_L\x10\a\x12\x11\n" +
-	"\rPIECE_GARBAGE\x10\b*|\n" +
+	"\rPIECE_GARBAGE\x10\b*\xa0\x02\n" +
 	"\tEventType\x12\x15\n" +
 	"\x11EVENT_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11EVENT_MATCH_START\x10\x01\x12\x13\n" +
 	"\x0fEVENT_GAME_OVER\x10\x02\x12\x10\n" +
 	"\fEVENT_WINNER\x10\x03\x12\x1a\n" +
-	"\x16EVENT_GARBAGE_RECEIVED\x10\x042I\n" +
+	"\x16EVENT_GARBAGE_RECEIVED\x10\x04\x12\x0f\n" +
+	"\vEVENT_COMBO\x10\x05\x12\x16\n" +
+	"\x12EVENT_BACK_TO_BACK\x10\x06\x12\x17\n" +
+	"\x13EVENT_PERFECT_CLEAR\x10\a\x12\x10\n" +
+	"\fEVENT_RESULT\x10\b\x12\x10\n" +
+	"\fEVENT_PAUSED\x10\t\x12\x11\n" +
+	"\rEVENT_RESUMED\x10\n" +
+	"\x12\x19\n" +
+	"\x15EVENT_PAUSE_REQUESTED\x10\v\x12\x0e\n" +
+	"\n" +
+	"EVENT_DROP\x10\f2\x89\x01\n" +
 	"\vGameService\x12:\n" +
-	"\x04Play\x12\x16.game.v1.ClientMessage\x1a\x16.game.v1.ServerMessage(\x010\x01B\x10Z\x0egame/v1;gamev1b\x06proto3"
+	"\x04Play\x12\x16.game.v1.ClientMessage\x1a\x16.game.v1.ServerMessage(\x010\x01\x12>\n" +
+	"\bSpectate\x12\x18.game.v1.SpectateRequest\x1a\x16.game.v1.ServerMessage0\x01B\x10Z\x0egame/v1;gamev1b\x06proto3"
 
 var (
 	file_game_v1_game_proto_rawDescOnce sync.Once
@@ -906,43 +1619,60 @@ func file_game_v1_game_proto_rawDescGZIP() []byte {
 }
 
 var file_game_v1_game_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_game_v1_game_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_game_v1_game_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
 var file_game_v1_game_proto_goTypes = []any{
-	(InputType)(0),        // 0: game.v1.InputType
-	(PieceType)(0),        // 1: game.v1.PieceType
-	(EventType)(0),        // 2: game.v1.EventType
-	(*ClientMessage)(nil), // 3: game.v1.ClientMessage
-	(*JoinRequest)(nil),   // 4: game.v1.JoinRequest
-	(*InputRequest)(nil),  // 5: game.v1.InputRequest
-	(*PingRequest)(nil),   // 6: game.v1.PingRequest
-	(*ServerMessage)(nil), // 7: game.v1.ServerMessage
-	(*StateUpdate)(nil),   // 8: game.v1.StateUpdate
-	(*GameEvent)(nil),     // 9: game.v1.GameEvent
-	(*PongResponse)(nil),  // 10: game.v1.PongResponse
-	(*Piece)(nil),         // 11: game.v1.Piece
-	nil,                   // 12: game.v1.GameEvent.MetadataEntry
+	(InputType)(0),          // 0: game.v1.InputType
+	(PieceType)(0),          // 1: game.v1.PieceType
+	(EventType)(0),          // 2: game.v1.EventType
+	(*ClientMessage)(nil),   // 3: game.v1.ClientMessage
+	(*JoinRequest)(nil),     // 4: game.v1.JoinRequest
+	(*InputRequest)(nil),    // 5: game.v1.InputRequest
+	(*SpectateRequest)(nil), // 6: game.v1.SpectateRequest
+	(*ResyncRequest)(nil),   // 7: game.v1.ResyncRequest
+	(*PingRequest)(nil),     // 8: game.v1.PingRequest
+	(*ServerMessage)(nil),   // 9: game.v1.ServerMessage
+	(*SessionInfo)(nil),     // 10: game.v1.SessionInfo
+	(*StateUpdate)(nil),     // 11: game.v1.StateUpdate
+	(*StateDelta)(nil),      // 12: game.v1.StateDelta
+	(*CellChange)(nil),      // 13: game.v1.CellChange
+	(*PieceQueue)(nil),      // 14: game.v1.PieceQueue
+	(*GameEvent)(nil),       // 15: game.v1.GameEvent
+	(*PongResponse)(nil),    // 16: game.v1.PongResponse
+	(*Piece)(nil),           // 17: game.v1.Piece
+	nil,                     // 18: game.v1.GameEvent.MetadataEntry
 }
 var file_game_v1_game_proto_depIdxs = []int32{
 	4,  // 0: game.v1.ClientMessage.join:type_name -> game.v1.JoinRequest
 	5,  // 1: game.v1.ClientMessage.input:type_name -> game.v1.InputRequest
-	6,  // 2: game.v1.ClientMessage.ping:type_name -> game.v1.PingRequest
-	0,  // 3: game.v1.InputRequest.input:type_name -> game.v1.InputType
-	8,  // 4: game.v1.ServerMessage.state:type_name -> game.v1.StateUpdate
-	9,  // 5: game.v1.ServerMessage.event:type_name -> game.v1.GameEvent
-	10, // 6: game.v1.ServerMessage.pong:type_name -> game.v1.PongResponse
-	11, // 7: game.v1.StateUpdate.current_piece:type_name -> game.v1.Piece
-	1,  // 8: game.v1.StateUpdate.next_pieces:type_name -> game.v1.PieceType
-	1,  // 9: game.v1.StateUpdate.held_piece:type_name -> game.v1.PieceType
-	2,  // 10: game.v1.GameEvent.type:type_name -> game.v1.EventType
-	12, // 11: game.v1.GameEvent.metadata:type_name -> game.v1.GameEvent.MetadataEntry
-	1,  // 12: game.v1.Piece.type:type_name -> game.v1.PieceType
-	3,  // 13: game.v1.GameService.Play:input_type -> game.v1.ClientMessage
-	7,  // 14: game.v1.GameService.Play:output_type -> game.v1.ServerMessage
-	14, // [14:15] is the sub-list for method output_type
-	13, // [13:14] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	8,  // 2: game.v1.ClientMessage.ping:type_name -> game.v1.PingRequest
+	7,  // 3: game.v1.ClientMessage.resync:type_name -> game.v1.ResyncRequest
+	0,  // 4: game.v1.InputRequest.input:type_name -> game.v1.InputType
+	11, // 5: game.v1.ServerMessage.state:type_name -> game.v1.StateUpdate
+	15, // 6: game.v1.ServerMessage.event:type_name -> game.v1.GameEvent
+	16, // 7: game.v1.ServerMessage.pong:type_name -> game.v1.PongResponse
+	10, // 8: game.v1.ServerMessage.session:type_name -> game.v1.SessionInfo
+	12, // 9: game.v1.ServerMessage.delta:type_name -> game.v1.StateDelta
+	17, // 10: game.v1.StateUpdate.current_piece:type_name -> game.v1.Piece
+	1,  // 11: game.v1.StateUpdate.next_pieces:type_name -> game.v1.PieceType
+	1,  // 12: game.v1.StateUpdate.held_piece:type_name -> game.v1.PieceType
+	13, // 13: game.v1.StateDelta.cells:type_name -> game.v1.CellChange
+	17, // 14: game.v1.StateDelta.current_piece:type_name -> game.v1.Piece
+	14, // 15: game.v1.StateDelta.next_pieces:type_name -> game.v1.PieceQueue
+	1,  // 16: game.v1.StateDelta.held_piece:type_name -> game.v1.PieceType
+	1,  // 17: game.v1.CellChange.type:type_name -> game.v1.PieceType
+	1,  // 18: game.v1.PieceQueue.pieces:type_name -> game.v1.PieceType
+	2,  // 19: game.v1.GameEvent.type:type_name -> game.v1.EventType
+	18, // 20: game.v1.GameEvent.metadata:type_name -> game.v1.GameEvent.MetadataEntry
+	1,  // 21: game.v1.Piece.type:type_name -> game.v1.PieceType
+	3,  // 22: game.v1.GameService.Play:input_type -> game.v1.ClientMessage
+	6,  // 23: game.v1.GameService.Spectate:input_type -> game.v1.SpectateRequest
+	9,  // 24: game.v1.GameService.Play:output_type -> game.v1.ServerMessage
+	9,  // 25: game.v1.GameService.Spectate:output_type -> game.v1.ServerMessage
+	24, // [24:26] is the sub-list for method output_type
+	22, // [22:24] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_game_v1_game_proto_init() }
@@ -954,19 +1684,23 @@ func file_game_v1_game_proto_init() {
 		(*ClientMessage_Join)(nil),
 		(*ClientMessage_Input)(nil),
 		(*ClientMessage_Ping)(nil),
+		(*ClientMessage_Resync)(nil),
 	}
-	file_game_v1_game_proto_msgTypes[4].OneofWrappers = []any{
+	file_game_v1_game_proto_msgTypes[6].OneofWrappers = []any{
 		(*ServerMessage_State)(nil),
 		(*ServerMessage_Event)(nil),
 		(*ServerMessage_Pong)(nil),
+		(*ServerMessage_Session)(nil),
+		(*ServerMessage_Delta)(nil),
 	}
+	file_game_v1_game_proto_msgTypes[9].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_v1_game_proto_rawDesc), len(file_game_v1_game_proto_rawDesc)),
 			NumEnums:      3,
-			NumMessages:   10,
+			NumMessages:   16,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
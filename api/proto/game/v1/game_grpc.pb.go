@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	GameService_Play_FullMethodName = "/game.v1.GameService/Play"
+	GameService_Play_FullMethodName     = "/game.v1.GameService/Play"
+	GameService_Spectate_FullMethodName = "/game.v1.GameService/Spectate"
 )
 
 // GameServiceClient is the client API for GameService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type GameServiceClient interface {
 	Play(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClientMessage, ServerMessage], error)
+	Spectate(ctx context.Context, in *SpectateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServerMessage], error)
 }
 
 type gameServiceClient struct {
@@ -50,11 +52,31 @@ func (c *gameServiceClient) Play(ctx context.Context, opts ...grpc.CallOption) (
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GameService_PlayClient = grpc.BidiStreamingClient[ClientMessage, ServerMessage]
 
+func (c *gameServiceClient) Spectate(ctx context.Context, in *SpectateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ServerMessage], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GameService_ServiceDesc.Streams[1], GameService_Spectate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SpectateRequest, ServerMessage]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GameService_SpectateClient = grpc.ServerStreamingClient[ServerMessage]
+
 // GameServiceServer is the server API for GameService service.
 // All implementations must embed UnimplementedGameServiceServer
 // for forward compatibility.
 type GameServiceServer interface {
 	Play(grpc.BidiStreamingServer[ClientMessage, ServerMessage]) error
+	Spectate(*SpectateRequest, grpc.ServerStreamingServer[ServerMessage]) error
 	mustEmbedUnimplementedGameServiceServer()
 }
 
@@ -68,6 +90,9 @@ type UnimplementedGameServiceServer struct{}
 func (UnimplementedGameServiceServer) Play(grpc.BidiStreamingServer[ClientMessage, ServerMessage]) error {
 	return status.Error(codes.Unimplemented, "method Play not implemented")
 }
+func (UnimplementedGameServiceServer) Spectate(*SpectateRequest, grpc.ServerStreamingServer[ServerMessage]) error {
+	return status.Error(codes.Unimplemented, "method Spectate not implemented")
+}
 func (UnimplementedGameServiceServer) mustEmbedUnimplementedGameServiceServer() {}
 func (UnimplementedGameServiceServer) testEmbeddedByValue()                     {}
 
@@ -96,6 +121,17 @@ func _GameService_Play_Handler(srv interface{}, stream grpc.ServerStream) error
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type GameService_PlayServer = grpc.BidiStreamingServer[ClientMessage, ServerMessage]
 
+func _GameService_Spectate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SpectateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GameServiceServer).Spectate(m, &grpc.GenericServerStream[SpectateRequest, ServerMessage]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GameService_SpectateServer = grpc.ServerStreamingServer[ServerMessage]
+
 // GameService_ServiceDesc is the grpc.ServiceDesc for GameService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -110,6 +146,11 @@ var GameService_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Spectate",
+			Handler:       _GameService_Spectate_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "game/v1/game.proto",
 }
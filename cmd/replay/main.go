@@ -0,0 +1,147 @@
+package main
+
+import (
+	"GoTetrisOnline/pkg/renderer"
+	"GoTetrisOnline/pkg/renderer/term"
+	"GoTetrisOnline/services/game-engine/domain"
+	"GoTetrisOnline/services/game-engine/wire"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	frameDuration = time.Second / renderer.FramesPerSecond
+	minSpeed      = 0.25
+	maxSpeed      = 16
+)
+
+func main() {
+	play := flag.Bool("play", false, "play the replay back in the terminal")
+	speed := flag.Float64("speed", 1, "playback speed multiplier")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: replay [-play] [-speed N] <file>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	replay, err := domain.LoadReplay(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("failed to load replay: %v", err)
+	}
+
+	if *play {
+		m := &model{
+			playback: domain.NewPlayback(replay),
+			replay:   replay,
+			speed:    min(max(*speed, minSpeed), maxSpeed),
+		}
+		if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := verify(replay); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func verify(replay *domain.Replay) error {
+	state := domain.Simulate(replay)
+	if state.Score != replay.Score {
+		return fmt.Errorf("score mismatch: replay recorded %d, simulation got %d", replay.Score, state.Score)
+	}
+
+	fmt.Printf("%s: score %d, lines %d, %d frames (%s) OK\n",
+		replay.Player, state.Score, state.Lines, state.Frame, renderer.ElapsedTime(state.Frame).Round(time.Millisecond))
+	return nil
+}
+
+type model struct {
+	playback *domain.Playback
+	replay   *domain.Replay
+	speed    float64
+	frames   float64
+	paused   bool
+}
+
+type tickMsg struct{}
+
+func tick() tea.Cmd {
+	return tea.Tick(frameDuration, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
+func (m *model) Init() tea.Cmd {
+	return tick()
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case " ":
+			m.paused = !m.paused
+		case "+", "=", "right":
+			m.speed = min(m.speed*2, maxSpeed)
+		case "-", "left":
+			m.speed = max(m.speed/2, minSpeed)
+		}
+
+	case tickMsg:
+		if !m.paused {
+			m.frames += m.speed
+			for ; m.frames >= 1; m.frames-- {
+				m.playback.Step()
+			}
+		}
+		return m, tick()
+	}
+
+	return m, nil
+}
+
+func (m *model) View() string {
+	state := m.playback.State
+	view := renderer.StateToView(wire.StateUpdate(state.GetSnapshot()))
+
+	status := fmt.Sprintf("%.2gx", m.speed)
+	switch {
+	case m.playback.Done():
+		status = "END"
+	case m.paused:
+		status = "PAUSED"
+	}
+
+	var sidebar strings.Builder
+	sidebar.WriteString(fmt.Sprintf("Player: %s\n", m.replay.Player))
+	sidebar.WriteString(fmt.Sprintf("Score: %d\n", state.Score))
+	sidebar.WriteString(fmt.Sprintf("Level: %d\n", state.Level))
+	sidebar.WriteString(fmt.Sprintf("Lines: %d\n", state.Lines))
+	sidebar.WriteString(fmt.Sprintf("Time: %s\n", renderer.ElapsedTime(state.Frame).Truncate(time.Second/10)))
+	sidebar.WriteString(fmt.Sprintf("Speed: %s\n", status))
+	sidebar.WriteString("\n")
+	sidebar.WriteString("CONTROLS\n")
+	sidebar.WriteString("+/-: Speed\n")
+	sidebar.WriteString("Space: Pause\n")
+	sidebar.WriteString("Q: Quit")
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		term.BoxStyle.Render(term.Board(view)),
+		term.BoxStyle.Render(sidebar.String()),
+	)
+}
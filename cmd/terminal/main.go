@@ -2,12 +2,22 @@ package main
 
 import (
 	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/backoff"
 	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/pkg/latency"
+	"GoTetrisOnline/pkg/predict"
 	"GoTetrisOnline/pkg/renderer"
+	"GoTetrisOnline/pkg/renderer/term"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -15,36 +25,27 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-var (
-	colorI     = lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Bold(true)  // Cyan
-	colorO     = lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true) // Yellow
-	colorT     = lipgloss.NewStyle().Foreground(lipgloss.Color("129")).Bold(true) // Purple
-	colorS     = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Bold(true)  // Green
-	colorZ     = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // Red
-	colorJ     = lipgloss.NewStyle().Foreground(lipgloss.Color("21")).Bold(true)  // Blue
-	colorL     = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true) // Orange
-	colorGray  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))            // Dark gray
-	colorPiece = lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Bold(true) // White (current piece)
-
-	boardStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(0, 1)
-
-	sidebarStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			Padding(0, 1)
-)
+const popupDuration = 1500 * time.Millisecond
+
+var colorPopup = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true) // Gold
 
 type model struct {
-	stream     pb.GameService_PlayClient
-	state      *pb.StateUpdate
-	gameOver   bool
-	finalScore int32
-	err        error
-	width      int
-	height     int
+	stream       pb.GameService_PlayClient
+	reconnecting bool
+	predictor    *predict.Predictor
+	pinger       *latency.Pinger
+	state        *pb.StateUpdate
+	timer        renderer.Timer
+	gameOver     bool
+	won          bool
+	finalScore   int32
+	resultTitle  string
+	result       []string
+	popup        string
+	popupID      int
+	err          error
+	width        int
+	height       int
 }
 
 type gameStateMsg struct {
@@ -53,14 +54,51 @@ type gameStateMsg struct {
 
 type gameOverMsg struct {
 	score int32
+	won   bool
+	// title and result summarise a game that reached its goal.
+	title  string
+	result []string
+}
+
+type popupMsg struct {
+	text string
+}
+
+type clearPopupMsg struct {
+	id int
 }
 
 type errMsg struct {
 	err error
 }
 
+type connectedMsg struct {
+	stream pb.GameService_PlayClient
+}
+
+type reconnectingMsg struct{}
+
+type pingMsg struct{}
+
+type timerMsg struct{}
+
+// resyncMsg asks the model to request a full state, since only the model
+// sends on the stream.
+type resyncMsg struct{}
+
 func main() {
-	conn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	server := flag.String("server", "localhost:50051", "game engine address")
+	matchID := flag.String("match", "room-1", "match to join or watch, single player modes default to a private match")
+	mode := flag.String("mode", "", "game mode: marathon, sprint, ultra, dig or zen")
+	goalLines := flag.Int("lines", 0, "lines to clear: 20, 40 or 100 in sprint mode, 10, 18 or 100 in dig mode")
+	cleanCheese := flag.Bool("clean", false, "keep the dig garbage hole in one column")
+	name := flag.String("name", "", "display name, ignored when the token carries one")
+	spectate := flag.Bool("spectate", false, "watch the match instead of playing")
+	token := flag.String("token", "", "signed join token, see cmd/tokengen")
+	prediction := flag.Bool("predict", true, "move the piece locally before the server confirms")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*server, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -68,58 +106,162 @@ func main() {
 
 	client := pb.NewGameServiceClient(conn)
 
-	stream, err := client.Play(context.Background())
-	if err != nil {
-		log.Printf("error creating stream: %v", err)
-		return
-	}
-
-	if err := stream.Send(&pb.ClientMessage{
-		Payload: &pb.ClientMessage_Join{
-			Join: &pb.JoinRequest{
-				MatchId: "room-1",
-				Token:   "token",
-			},
-		},
-	}); err != nil {
-		log.Printf("failed to send join: %v", err)
+	if *spectate {
+		runSpectator(client, *matchID)
 		return
 	}
 
 	m := model{
-		stream: stream,
+		predictor: predict.New(*prediction),
+		pinger:    latency.NewPinger(),
 	}
 
 	p := tea.NewProgram(&m, tea.WithAltScreen())
 
-	go receiveLoop(stream, p)
+	if !versus(*mode) && !flagSet("match") {
+		*matchID = privateMatchID(*mode)
+	}
+
+	join := &pb.JoinRequest{
+		MatchId:     *matchID,
+		Token:       *token,
+		Name:        *name,
+		Mode:        *mode,
+		CleanCheese: *cleanCheese,
+	}
+	if *mode == "dig" {
+		join.DigLines = int32(*goalLines) //nolint:gosec // validated by the engine
+	} else {
+		join.SprintLines = int32(*goalLines) //nolint:gosec // validated by the engine
+	}
+	go playLoop(client, join, m.pinger, p)
 
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func receiveLoop(stream pb.GameService_PlayClient, p *tea.Program) {
+// versus reports whether mode is played against other players.
+func versus(mode string) bool {
+	return mode == "" || mode == "marathon"
+}
+
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// privateMatchID returns a fresh match id so single player games do not end
+// up in someone else's match.
+func privateMatchID(mode string) string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return mode + "-" + hex.EncodeToString(b)
+}
+
+// playLoop keeps the game connected, resuming the session with backoff when
+// the stream drops.
+func playLoop(client pb.GameServiceClient, join *pb.JoinRequest, pinger *latency.Pinger, p *tea.Program) {
+	b := backoff.New()
+	resumeToken := ""
+
 	for {
-		msg, err := stream.Recv()
-		if err != nil {
+		connected, err := play(client, join, pinger, p, &resumeToken)
+		if err == nil {
+			return
+		}
+		if resumeToken == "" || !backoff.Retryable(err) {
 			p.Send(errMsg{err: err})
 			return
 		}
 
+		if connected {
+			b.Reset()
+		}
+		p.Send(reconnectingMsg{})
+		time.Sleep(b.Next())
+
+		join = &pb.JoinRequest{ResumeToken: resumeToken}
+	}
+}
+
+// play runs a single stream until it ends. It reports whether the engine
+// accepted the stream so the caller can reset its backoff.
+func play(client pb.GameServiceClient, join *pb.JoinRequest, pinger *latency.Pinger, p *tea.Program, resumeToken *string) (bool, error) {
+	stream, err := client.Play(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	if err := stream.Send(&pb.ClientMessage{
+		Payload: &pb.ClientMessage_Join{Join: join},
+	}); err != nil {
+		return false, err
+	}
+	p.Send(connectedMsg{stream: stream})
+
+	var state *pb.StateUpdate
+	connected := false
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return connected, nil
+		}
+		if err != nil {
+			return connected, err
+		}
+		connected = true
+
 		switch payload := msg.Payload.(type) {
+		case *pb.ServerMessage_Session:
+			*resumeToken = payload.Session.ResumeToken
 		case *pb.ServerMessage_State:
-			p.Send(gameStateMsg{state: payload.State})
+			state = payload.State
+			p.Send(gameStateMsg{state: state})
+		case *pb.ServerMessage_Pong:
+			pinger.Pong(payload.Pong)
+		case *pb.ServerMessage_Delta:
+			next, err := renderer.ApplyDelta(state, payload.Delta)
+			if err != nil {
+				p.Send(resyncMsg{})
+				continue
+			}
+			state = next
+			p.Send(gameStateMsg{state: state})
 		case *pb.ServerMessage_Event:
-			if payload.Event.Type == pb.EventType_EVENT_GAME_OVER {
+			switch payload.Event.Type {
+			case pb.EventType_EVENT_GAME_OVER:
 				p.Send(gameOverMsg{score: 0})
+			case pb.EventType_EVENT_WINNER:
+				p.Send(gameOverMsg{score: 0, won: true})
+			case pb.EventType_EVENT_RESULT:
+				p.Send(gameOverMsg{title: renderer.ResultTitle(payload.Event), result: renderer.ResultLines(payload.Event)})
+			case pb.EventType_EVENT_COMBO, pb.EventType_EVENT_BACK_TO_BACK, pb.EventType_EVENT_PERFECT_CLEAR,
+				pb.EventType_EVENT_PAUSED, pb.EventType_EVENT_RESUMED, pb.EventType_EVENT_PAUSE_REQUESTED:
+				p.Send(popupMsg{text: payload.Event.Message})
 			}
 		}
 	}
 }
 
 func (m *model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(pingTick(), timerTick())
+}
+
+// timerTick redraws the running timer between state updates.
+func timerTick() tea.Cmd {
+	return tea.Tick(50*time.Millisecond, func(time.Time) tea.Msg {
+		return timerMsg{}
+	})
+}
+
+func pingTick() tea.Cmd {
+	return tea.Tick(latency.Interval, func(time.Time) tea.Msg {
+		return pingMsg{}
+	})
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -127,7 +269,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		if m.gameOver {
 			if msg.String() == "q" || msg.String() == "ctrl+c" {
-				return m, tea.Quit
+				return m, m.quit()
 			}
 			return m, nil
 		}
@@ -135,7 +277,7 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var input pb.InputType
 		switch msg.String() {
 		case "q", "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
 		case "a", "left":
 			input = pb.InputType_INPUT_LEFT
 		case "d", "right":
@@ -148,31 +290,111 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			input = pb.InputType_INPUT_SOFT_DROP
 		case " ":
 			input = pb.InputType_INPUT_HARD_DROP
+		case "c":
+			input = pb.InputType_INPUT_HOLD
+		case "z":
+			input = pb.InputType_INPUT_UNDO
+		case "p":
+			input = pb.InputType_INPUT_PAUSE
 		default:
 			return m, nil
 		}
 
-		if err := m.stream.Send(&pb.ClientMessage{
+		if m.stream == nil || m.reconnecting {
+			return m, nil
+		}
+
+		// Pausing is not predicted, and the server ignores moves while the
+		// game is paused.
+		if input == pb.InputType_INPUT_PAUSE {
+			_ = m.stream.Send(&pb.ClientMessage{
+				Payload: &pb.ClientMessage_Input{Input: &pb.InputRequest{Input: input}},
+			})
+			return m, nil
+		}
+		if m.state != nil && m.state.Paused {
+			return m, nil
+		}
+
+		seq := m.predictor.Input(input)
+		if state := m.predictor.State(); state != nil {
+			m.state = state
+		}
+
+		// A failed send means the stream dropped; playLoop reconnects.
+		_ = m.stream.Send(&pb.ClientMessage{
 			Payload: &pb.ClientMessage_Input{
 				Input: &pb.InputRequest{
-					Input: input,
+					SequenceId: seq,
+					Input:      input,
 				},
 			},
-		}); err != nil {
-			m.err = err
-			return m, tea.Quit
+		})
+
+	case connectedMsg:
+		m.stream = msg.stream
+		m.reconnecting = false
+
+	case reconnectingMsg:
+		m.reconnecting = true
+
+	case pingMsg:
+		if m.gameOver {
+			return m, nil
+		}
+		if m.stream != nil && !m.reconnecting {
+			_ = m.stream.Send(&pb.ClientMessage{
+				Payload: &pb.ClientMessage_Ping{Ping: m.pinger.Ping()},
+			})
+		}
+		return m, pingTick()
+
+	case resyncMsg:
+		if m.stream != nil {
+			_ = m.stream.Send(&pb.ClientMessage{
+				Payload: &pb.ClientMessage_Resync{Resync: &pb.ResyncRequest{}},
+			})
+		}
+
+	case timerMsg:
+		if m.gameOver {
+			return m, nil
 		}
+		return m, timerTick()
 
 	case gameStateMsg:
-		m.state = msg.state
+		m.predictor.Reconcile(msg.state)
+		m.state = m.predictor.State()
+		m.timer.Update(msg.state.TickId, time.Now())
+		m.timer.SetPaused(msg.state.Paused)
 
 	case gameOverMsg:
 		m.gameOver = true
+		m.won = msg.won
+		m.resultTitle = msg.title
+		m.result = msg.result
+		m.timer.Stop()
 		if m.state != nil {
 			m.finalScore = m.state.Score
 		}
 
+	case popupMsg:
+		m.popup = msg.text
+		m.popupID++
+		id := m.popupID
+		return m, tea.Tick(popupDuration, func(time.Time) tea.Msg {
+			return clearPopupMsg{id: id}
+		})
+
+	case clearPopupMsg:
+		if msg.id == m.popupID {
+			m.popup = ""
+		}
+
 	case errMsg:
+		if m.gameOver {
+			return m, nil
+		}
 		m.err = msg.err
 		return m, tea.Quit
 
@@ -184,73 +406,90 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// quit closes the stream cleanly so the engine ends the session instead of
+// holding the game for a reconnect.
+func (m *model) quit() tea.Cmd {
+	if m.stream != nil {
+		_ = m.stream.CloseSend()
+	}
+	return tea.Quit
+}
+
 func (m *model) View() string {
 	if m.err != nil {
 		return fmt.Sprintf("Error: %v\n", m.err)
 	}
 
 	if m.gameOver {
-		return fmt.Sprintf("\nGAME OVER!\n\nFinal Score: %d\n\nPress 'q' to quit\n", m.finalScore)
-	}
+		if m.result != nil {
+			return fmt.Sprintf("\n%s\n\n%s\n\nPress 'q' to quit\n", m.resultTitle, strings.Join(m.result, "\n"))
+		}
 
-	if m.state == nil {
-		return "Connecting to game server...\n"
+		title := "GAME OVER!"
+		if m.won {
+			title = "YOU WIN!"
+		}
+		return fmt.Sprintf("\n%s\n\nFinal Score: %d\n\nPress 'q' to quit\n", title, m.finalScore)
 	}
 
-	return renderGame(m.state)
-}
-
-func renderGame(state *pb.StateUpdate) string {
-	view := renderer.StateToView(state)
-	boardContent := renderBoard(view)
-	sidebarContent := renderSidebar(view)
+	if m.reconnecting {
+		return "Connection lost, reconnecting...\n"
+	}
 
-	board := boardStyle.Render(boardContent)
-	sidebar := sidebarStyle.Render(sidebarContent)
+	if m.state == nil {
+		return "Waiting for other players...\n"
+	}
 
-	return lipgloss.JoinHorizontal(lipgloss.Top, board, sidebar)
+	view := renderer.StateToView(m.state)
+	view.Elapsed = m.timer.Elapsed(time.Now())
+	popup := m.popup
+	if view.Paused && popup == "" {
+		popup = "PAUSED - press 'p' to resume"
+	}
+	return renderGame(view, popup, m.pinger.RTT())
 }
 
-func renderBoard(view *renderer.GameView) string {
-	var b strings.Builder
-
-	for y := 0; y < view.Height; y++ {
-		for x := 0; x < view.Width; x++ {
-			cell := view.Board[y][x]
-			var char string
-
-			switch cell.Type {
-			case renderer.CellPiece:
-				char = colorPiece.Render("██")
-			case renderer.CellFixed:
-				char = getColorForPiece(cell.PieceType).Render("██")
-			default:
-				char = colorGray.Render("░░")
-			}
-
-			b.WriteString(char)
-		}
+// renderGame draws a board with its sidebar. A zero rtt hides the latency.
+func renderGame(view *renderer.GameView, popup string, rtt time.Duration) string {
+	meter := term.MeterStyle.Render(term.GarbageMeter(view))
+	board := term.BoxStyle.Render(term.Board(view))
+	sidebar := term.BoxStyle.Render(renderSidebar(view, rtt))
 
-		if y < view.Height-1 {
-			b.WriteString("\n")
-		}
+	game := lipgloss.JoinHorizontal(lipgloss.Top, meter, board, sidebar)
+	if popup == "" {
+		return game
 	}
-
-	return b.String()
+	return lipgloss.JoinVertical(lipgloss.Left, game, colorPopup.Render(popup))
 }
 
-func renderSidebar(view *renderer.GameView) string {
+func renderSidebar(view *renderer.GameView, rtt time.Duration) string {
 	var b strings.Builder
 
 	b.WriteString("NEXT:\n\n")
 
 	if view.NextPiece != core.PieceNone {
-		b.WriteString(renderNextPiece(view.NextPiece))
+		b.WriteString(term.Piece(view.NextPiece))
 	}
 
 	b.WriteString("\n\n")
+	b.WriteString("HOLD:\n\n")
+
+	if view.HeldPiece != core.PieceNone {
+		b.WriteString(term.Piece(view.HeldPiece))
+		b.WriteString("\n\n")
+	}
+
 	b.WriteString(fmt.Sprintf("Score: %d\n", view.Score))
-	b.WriteString(fmt.Sprintf("Level: %d\n", view.Level))
+	if view.ShowTimer() {
+		for _, line := range view.TimerLines() {
+			b.WriteString(line + "\n")
+		}
+	} else {
+		b.WriteString(fmt.Sprintf("Level: %d\n", view.Level))
+	}
+	if rtt > 0 {
+		b.WriteString(fmt.Sprintf("Ping: %d ms\n", rtt.Milliseconds()))
+	}
 	b.WriteString("\n")
 	b.WriteString("CONTROLS\n")
 	b.WriteString("A: Left\n")
@@ -259,90 +498,12 @@ func renderSidebar(view *renderer.GameView) string {
 	b.WriteString("E: Rotate CCW\n")
 	b.WriteString("S: Soft Drop\n")
 	b.WriteString("Space: Drop!\n")
-	b.WriteString("Q: Quit")
-
-	return b.String()
-}
-
-func renderNextPiece(t core.PieceType) string {
-	var b strings.Builder
-	minos := core.GetRotatedMinos(t, 0)
-
-	minX, maxX := 0, 0
-	minY, maxY := 0, 0
-	for i, m := range minos {
-		if i == 0 {
-			minX, maxX = m.X, m.X
-			minY, maxY = m.Y, m.Y
-		} else {
-			if m.X < minX {
-				minX = m.X
-			}
-			if m.X > maxX {
-				maxX = m.X
-			}
-			if m.Y < minY {
-				minY = m.Y
-			}
-			if m.Y > maxY {
-				maxY = m.Y
-			}
-		}
-	}
-
-	width := maxX - minX + 1
-	height := maxY - minY + 1
-	gridSize := 4
-	offsetX := (gridSize - width) / 2
-	offsetY := (gridSize - height) / 2
-
-	grid := make([][]bool, gridSize)
-	for i := range grid {
-		grid[i] = make([]bool, gridSize)
-	}
-
-	for _, m := range minos {
-		x := m.X - minX + offsetX
-		y := m.Y - minY + offsetY
-		if x >= 0 && x < gridSize && y >= 0 && y < gridSize {
-			grid[y][x] = true
-		}
-	}
-
-	style := getColorForPiece(t)
-	for y := 0; y < gridSize; y++ {
-		for x := 0; x < gridSize; x++ {
-			if grid[y][x] {
-				b.WriteString(style.Render("██"))
-			} else {
-				b.WriteString(colorGray.Render("░░"))
-			}
-		}
-		if y < gridSize-1 {
-			b.WriteString("\n")
-		}
+	b.WriteString("C: Hold\n")
+	b.WriteString("P: Pause\n")
+	if view.Mode == renderer.ModeZen {
+		b.WriteString("Z: Undo\n")
 	}
+	b.WriteString("Q: Quit")
 
 	return b.String()
 }
-
-func getColorForPiece(t core.PieceType) lipgloss.Style {
-	switch t {
-	case core.PieceI:
-		return colorI
-	case core.PieceO:
-		return colorO
-	case core.PieceT:
-		return colorT
-	case core.PieceS:
-		return colorS
-	case core.PieceZ:
-		return colorZ
-	case core.PieceJ:
-		return colorJ
-	case core.PieceL:
-		return colorL
-	default:
-		return colorGray
-	}
-}
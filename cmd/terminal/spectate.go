@@ -0,0 +1,156 @@
+package main
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/renderer"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type spectatorModel struct {
+	matchID string
+	players []string
+	states  map[string]*pb.StateUpdate
+	results map[string]string
+	ended   bool
+	err     error
+}
+
+type spectateStateMsg struct {
+	state *pb.StateUpdate
+}
+
+type spectateResultMsg struct {
+	playerID string
+	result   string
+}
+
+type spectateEndMsg struct{}
+
+func runSpectator(client pb.GameServiceClient, matchID string) {
+	stream, err := client.Spectate(context.Background(), &pb.SpectateRequest{MatchId: matchID})
+	if err != nil {
+		log.Printf("error creating stream: %v", err)
+		return
+	}
+
+	m := &spectatorModel{
+		matchID: matchID,
+		states:  make(map[string]*pb.StateUpdate),
+		results: make(map[string]string),
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	go spectateLoop(stream, p)
+
+	if _, err := p.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func spectateLoop(stream pb.GameService_SpectateClient, p *tea.Program) {
+	states := make(map[string]*pb.StateUpdate)
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				p.Send(spectateEndMsg{})
+			} else {
+				p.Send(errMsg{err: err})
+			}
+			return
+		}
+
+		switch payload := msg.Payload.(type) {
+		case *pb.ServerMessage_State:
+			states[payload.State.PlayerId] = payload.State
+			p.Send(spectateStateMsg{state: payload.State})
+		case *pb.ServerMessage_Delta:
+			// A spectator cannot ask for a resync; a board that misses its
+			// base waits for the next keyframe.
+			state, err := renderer.ApplyDelta(states[payload.Delta.PlayerId], payload.Delta)
+			if err != nil {
+				continue
+			}
+			states[state.PlayerId] = state
+			p.Send(spectateStateMsg{state: state})
+		case *pb.ServerMessage_Event:
+			playerID := payload.Event.Metadata["player_id"]
+			switch payload.Event.Type {
+			case pb.EventType_EVENT_GAME_OVER:
+				p.Send(spectateResultMsg{playerID: playerID, result: "GAME OVER"})
+			case pb.EventType_EVENT_WINNER:
+				p.Send(spectateResultMsg{playerID: playerID, result: "WINNER"})
+			case pb.EventType_EVENT_RESULT:
+				p.Send(spectateResultMsg{playerID: playerID, result: renderer.ResultTitle(payload.Event) + " " + payload.Event.Message})
+			}
+		}
+	}
+}
+
+func (m *spectatorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *spectatorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "q" || msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+	case spectateStateMsg:
+		id := msg.state.PlayerId
+		if !slices.Contains(m.players, id) {
+			m.players = append(m.players, id)
+			slices.Sort(m.players)
+		}
+		m.states[id] = msg.state
+
+	case spectateResultMsg:
+		m.results[msg.playerID] = msg.result
+
+	case spectateEndMsg:
+		m.ended = true
+
+	case errMsg:
+		m.err = msg.err
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *spectatorModel) View() string {
+	if m.err != nil {
+		return fmt.Sprintf("Error: %v\n", m.err)
+	}
+
+	header := fmt.Sprintf("Spectating %s", m.matchID)
+	if m.ended {
+		header += " - match over, press 'q' to quit"
+	}
+
+	if len(m.players) == 0 {
+		return header + "\n\nWaiting for players...\n"
+	}
+
+	boards := make([]string, 0, len(m.players))
+	for _, id := range m.players {
+		title := id
+		if result, ok := m.results[id]; ok {
+			title += " - " + result
+		}
+		boards = append(boards, lipgloss.JoinVertical(lipgloss.Left, title, renderGame(renderer.StateToView(m.states[id]), "", 0)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, "", lipgloss.JoinHorizontal(lipgloss.Top, boards...))
+}
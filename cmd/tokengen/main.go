@@ -0,0 +1,65 @@
+package main
+
+import (
+	"GoTetrisOnline/services/game-engine/auth"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const keySize = 32
+
+func main() {
+	keyPath := flag.String("key", "auth.key", "path to the hex encoded signing key")
+	genKey := flag.Bool("genkey", false, "write a new random signing key to -key and exit")
+	player := flag.String("player", "", "player id")
+	name := flag.String("name", "", "display name, defaults to the player id")
+	ttl := flag.Duration("ttl", 24*time.Hour, "token lifetime")
+	flag.Parse()
+
+	if *genKey {
+		if err := writeKey(*keyPath); err != nil {
+			log.Fatalf("failed to write key: %v", err)
+		}
+		log.Printf("Signing key written to %s", *keyPath)
+		return
+	}
+
+	if *player == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *name == "" {
+		*name = *player
+	}
+
+	key, err := auth.LoadKey(*keyPath)
+	if err != nil {
+		log.Fatalf("failed to load key: %v", err)
+	}
+
+	signer, err := auth.NewHMAC(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	token, err := signer.Sign(auth.Identity{PlayerID: *player, DisplayName: *name}, time.Now().Add(*ttl))
+	if err != nil {
+		log.Fatalf("failed to sign token: %v", err)
+	}
+
+	fmt.Println(token)
+}
+
+func writeKey(path string) error {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0o600)
+}
@@ -0,0 +1,54 @@
+package backoff
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultMin = 250 * time.Millisecond
+	DefaultMax = 5 * time.Second
+)
+
+// Backoff produces exponentially growing delays between reconnect attempts,
+// with jitter so clients that dropped together do not retry in lockstep.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+
+	attempt int
+}
+
+func New() *Backoff {
+	return &Backoff{Min: DefaultMin, Max: DefaultMax}
+}
+
+func (b *Backoff) Next() time.Duration {
+	d := b.Min << min(b.attempt, 16)
+	if d <= 0 || d > b.Max {
+		d = b.Max
+	}
+	b.attempt++
+
+	// Wait between half and the full delay.
+	return d/2 + rand.N(d/2+1)
+}
+
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
+
+// Retryable reports whether reconnecting can help after err. Rejections by
+// the engine, such as an expired resume token, are final. A resume refused
+// with FailedPrecondition is retried: the engine keeps the session attached
+// to a half-open stream until that stream times out.
+func Retryable(err error) bool {
+	switch status.Code(err) {
+	case codes.NotFound, codes.Unauthenticated, codes.InvalidArgument, codes.PermissionDenied:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package backoff
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackoff_GrowsUpToMax(t *testing.T) {
+	b := &Backoff{Min: 100 * time.Millisecond, Max: time.Second}
+
+	for i := range 10 {
+		d := b.Next()
+		limit := min(b.Min<<i, b.Max)
+		if d < limit/2 || d > limit {
+			t.Errorf("attempt %d: delay %v outside [%v, %v]", i, d, limit/2, limit)
+		}
+	}
+
+	b.Reset()
+	if d := b.Next(); d > b.Min {
+		t.Errorf("reset must start over from Min, got %v", d)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	if !Retryable(errors.New("connection reset")) {
+		t.Error("transport errors must be retried")
+	}
+	if !Retryable(status.Error(codes.Unavailable, "engine down")) {
+		t.Error("unavailable engine must be retried")
+	}
+	if !Retryable(status.Error(codes.FailedPrecondition, "session is still attached")) {
+		t.Error("resuming a session that is still attached must be retried")
+	}
+	if Retryable(status.Error(codes.NotFound, "unknown resume token")) {
+		t.Error("expired sessions must not be retried")
+	}
+}
@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the name of every environment variable that overrides a
+// config value, for example TETRIS_ENGINE_LISTEN.
+const EnvPrefix = "TETRIS_"
+
+// Config holds the settings of the engine and the gateway. Values come from
+// Default, then the YAML file, then the environment.
+type Config struct {
+	Engine  Engine  `yaml:"engine"`
+	Gateway Gateway `yaml:"gateway"`
+	Game    Game    `yaml:"game"`
+}
+
+type Engine struct {
+	Listen         string        `yaml:"listen"`
+	ReplayDir      string        `yaml:"replay_dir"`
+	AuthKey        string        `yaml:"auth_key"`
+	AllowAnonymous bool          `yaml:"allow_anonymous"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	ResumeGrace    time.Duration `yaml:"resume_grace"`
+}
+
+type Gateway struct {
+	Listen         string        `yaml:"listen"`
+	EngineAddr     string        `yaml:"engine_addr"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	ReadTimeout    time.Duration `yaml:"read_timeout"`
+	WriteTimeout   time.Duration `yaml:"write_timeout"`
+	AllowedOrigins []string      `yaml:"allowed_origins"`
+}
+
+// Game holds the default rules for new matches.
+type Game struct {
+	StartLevel    int32         `yaml:"start_level"`
+	LockDelay     time.Duration `yaml:"lock_delay"`
+	MaxLockResets int           `yaml:"max_lock_resets"`
+	LinesPerLevel int32         `yaml:"lines_per_level"`
+	Randomizer    string        `yaml:"randomizer"`
+	SprintLines   int32         `yaml:"sprint_lines"`
+	UltraTime     time.Duration `yaml:"ultra_time"`
+	DigLines      int32         `yaml:"dig_lines"`
+}
+
+func Default() *Config {
+	return &Config{
+		Engine: Engine{
+			Listen:      ":50051",
+			ReplayDir:   "replays",
+			IdleTimeout: 15 * time.Second,
+			ResumeGrace: 30 * time.Second,
+		},
+		Gateway: Gateway{
+			Listen:         ":8081",
+			EngineAddr:     "localhost:50051",
+			IdleTimeout:    15 * time.Second,
+			ReadTimeout:    10 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			AllowedOrigins: []string{"*"},
+		},
+		Game: Game{
+			StartLevel:    1,
+			LockDelay:     500 * time.Millisecond,
+			MaxLockResets: 15,
+			LinesPerLevel: 10,
+			Randomizer:    "7bag",
+			SprintLines:   40,
+			UltraTime:     2 * time.Minute,
+			DigLines:      10,
+		},
+	}
+}
+
+// Load returns the default config overridden by the YAML file at path, if
+// path is not empty, and then by the environment.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.parse(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) parse(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	err := dec.Decode(c)
+	if errors.Is(err, io.EOF) {
+		// An empty file keeps the defaults.
+		return nil
+	}
+	return err
+}
+
+type setter func(value string) error
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	vars := map[string]setter{
+		"ENGINE_LISTEN":          setString(&c.Engine.Listen),
+		"ENGINE_REPLAY_DIR":      setString(&c.Engine.ReplayDir),
+		"ENGINE_AUTH_KEY":        setString(&c.Engine.AuthKey),
+		"ENGINE_ALLOW_ANONYMOUS": setBool(&c.Engine.AllowAnonymous),
+		"ENGINE_IDLE_TIMEOUT":    setDuration(&c.Engine.IdleTimeout),
+		"ENGINE_RESUME_GRACE":    setDuration(&c.Engine.ResumeGrace),
+
+		"GATEWAY_LISTEN":          setString(&c.Gateway.Listen),
+		"GATEWAY_ENGINE_ADDR":     setString(&c.Gateway.EngineAddr),
+		"GATEWAY_IDLE_TIMEOUT":    setDuration(&c.Gateway.IdleTimeout),
+		"GATEWAY_READ_TIMEOUT":    setDuration(&c.Gateway.ReadTimeout),
+		"GATEWAY_WRITE_TIMEOUT":   setDuration(&c.Gateway.WriteTimeout),
+		"GATEWAY_ALLOWED_ORIGINS": setList(&c.Gateway.AllowedOrigins),
+
+		"GAME_START_LEVEL":     setInt32(&c.Game.StartLevel),
+		"GAME_LOCK_DELAY":      setDuration(&c.Game.LockDelay),
+		"GAME_MAX_LOCK_RESETS": setInt(&c.Game.MaxLockResets),
+		"GAME_LINES_PER_LEVEL": setInt32(&c.Game.LinesPerLevel),
+		"GAME_RANDOMIZER":      setString(&c.Game.Randomizer),
+		"GAME_SPRINT_LINES":    setInt32(&c.Game.SprintLines),
+		"GAME_ULTRA_TIME":      setDuration(&c.Game.UltraTime),
+		"GAME_DIG_LINES":       setInt32(&c.Game.DigLines),
+	}
+
+	for name, set := range vars {
+		value, ok := lookup(EnvPrefix + name)
+		if !ok {
+			continue
+		}
+		if err := set(value); err != nil {
+			return fmt.Errorf("%s%s: %w", EnvPrefix, name, err)
+		}
+	}
+	return nil
+}
+
+func setString(dst *string) setter {
+	return func(value string) error {
+		*dst = value
+		return nil
+	}
+}
+
+func setDuration(dst *time.Duration) setter {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*dst = d
+		return nil
+	}
+}
+
+func setBool(dst *bool) setter {
+	return func(value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*dst = b
+		return nil
+	}
+}
+
+func setInt(dst *int) setter {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	}
+}
+
+func setInt32(dst *int32) setter {
+	return func(value string) error {
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		*dst = int32(n)
+		return nil
+	}
+}
+
+// setList parses a comma separated list.
+func setList(dst *[]string) setter {
+	return func(value string) error {
+		var list []string
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*dst = list
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_ExampleMatchesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("config.example.yaml drifted from the defaults:\ngot  %+v\nwant %+v", cfg, Default())
+	}
+}
+
+func TestLoad_FileThenEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "engine:\n  listen: \":6000\"\n  idle_timeout: 5s\ngame:\n  start_level: 5\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TETRIS_ENGINE_LISTEN", ":7000")
+	t.Setenv("TETRIS_GATEWAY_ALLOWED_ORIGINS", "example.com, *.example.com")
+	t.Setenv("TETRIS_ENGINE_ALLOW_ANONYMOUS", "true")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	if cfg.Engine.Listen != ":7000" {
+		t.Errorf("environment must override the file, got %q", cfg.Engine.Listen)
+	}
+	if cfg.Engine.IdleTimeout != 5*time.Second || cfg.Game.StartLevel != 5 {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.Engine.ResumeGrace != Default().Engine.ResumeGrace {
+		t.Error("values missing from the file must keep their defaults")
+	}
+	if want := []string{"example.com", "*.example.com"}; !reflect.DeepEqual(cfg.Gateway.AllowedOrigins, want) {
+		t.Errorf("expected origins %v, got %v", want, cfg.Gateway.AllowedOrigins)
+	}
+	if !cfg.Engine.AllowAnonymous {
+		t.Error("expected anonymous play allowed from the environment")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("engine:\n  listn: \":6000\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("unknown keys must be rejected")
+	}
+
+	t.Setenv("TETRIS_ENGINE_IDLE_TIMEOUT", "soon")
+	_, err := Load("")
+	if err == nil || !strings.Contains(err.Error(), "TETRIS_ENGINE_IDLE_TIMEOUT") {
+		t.Errorf("expected an error naming the variable, got %v", err)
+	}
+}
@@ -1,27 +1,69 @@
 package core
 
-import "math/rand/v2"
+import (
+	"encoding"
+	"errors"
+	"math/rand/v2"
+)
 
 var allPieceTypes = [7]PieceType{PieceI, PieceO, PieceT, PieceS, PieceZ, PieceJ, PieceL}
 
 type Bag struct {
 	buf  []PieceType
 	head int
+	src  rand.Source
+	rng  *rand.Rand
+	size int
 }
 
+// BagState is a saved position of a Bag: the pieces already drawn into its
+// buffer and the state of its random source.
+type BagState struct {
+	buf  []PieceType
+	head int
+	src  []byte
+}
+
+var errSourceNotSaved = errors.New("bag: random source cannot be saved")
+
 func NewBag() *Bag {
-	b := &Bag{}
+	return NewSeededBag(rand.Uint64())
+}
+
+// NewSeededBag returns a bag whose piece order is fully determined by seed.
+func NewSeededBag(seed uint64) *Bag {
+	return NewBagWithSource(rand.NewPCG(seed, seed))
+}
+
+func NewBagWithSource(src rand.Source) *Bag {
+	return newBag(src, 1)
+}
+
+// NewBag14WithSource returns a bag holding two copies of every piece.
+func NewBag14WithSource(src rand.Source) *Bag {
+	return newBag(src, 2)
+}
+
+func newBag(src rand.Source, copies int) *Bag {
+	b := &Bag{
+		src:  src,
+		rng:  rand.New(src),
+		size: copies * len(allPieceTypes),
+	}
 	b.push()
 	b.push()
 	return b
 }
 
 func (b *Bag) push() {
-	set := allPieceTypes
-	rand.Shuffle(len(set), func(i, j int) {
+	set := make([]PieceType, 0, b.size)
+	for len(set) < b.size {
+		set = append(set, allPieceTypes[:]...)
+	}
+	b.rng.Shuffle(len(set), func(i, j int) {
 		set[i], set[j] = set[j], set[i]
 	})
-	b.buf = append(b.buf, set[:]...)
+	b.buf = append(b.buf, set...)
 }
 
 func (b *Bag) available() int {
@@ -29,14 +71,14 @@ func (b *Bag) available() int {
 }
 
 func (b *Bag) Next() PieceType {
-	if b.available() < 7 {
+	if b.available() < b.size {
 		b.push()
 	}
 
 	p := b.buf[b.head]
 	b.head++
 
-	if b.head >= 7 {
+	if b.head >= b.size {
 		b.buf = append(b.buf[:0], b.buf[b.head:]...)
 		b.head = 0
 	}
@@ -52,3 +94,38 @@ func (b *Bag) Peek(n int) []PieceType {
 	copy(out, b.buf[b.head:b.head+n])
 	return out
 }
+
+// Snapshot saves the bag's position. It fails if the bag's random source does
+// not implement encoding.BinaryMarshaler; the seeded sources of this package
+// do.
+func (b *Bag) Snapshot() (BagState, error) {
+	m, ok := b.src.(encoding.BinaryMarshaler)
+	if !ok {
+		return BagState{}, errSourceNotSaved
+	}
+	src, err := m.MarshalBinary()
+	if err != nil {
+		return BagState{}, err
+	}
+
+	return BagState{
+		buf:  append([]PieceType(nil), b.buf...),
+		head: b.head,
+		src:  src,
+	}, nil
+}
+
+// Restore rewinds the bag to a position saved by Snapshot.
+func (b *Bag) Restore(state BagState) error {
+	u, ok := b.src.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return errSourceNotSaved
+	}
+	if err := u.UnmarshalBinary(state.src); err != nil {
+		return err
+	}
+
+	b.buf = append(b.buf[:0], state.buf...)
+	b.head = state.head
+	return nil
+}
@@ -1,6 +1,10 @@
 package core
 
-import "testing"
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
 
 func TestBag_EvenDistribution(t *testing.T) {
 	b := NewBag()
@@ -78,3 +82,62 @@ func TestBag_PeekBeyondCurrentSet(t *testing.T) {
 		}
 	}
 }
+
+func TestBag_SameSeedSameSequence(t *testing.T) {
+	a := NewSeededBag(42)
+	b := NewSeededBag(42)
+
+	for i := range 100 {
+		if pa, pb := a.Next(), b.Next(); pa != pb {
+			t.Fatalf("step %d: %v != %v", i, pa, pb)
+		}
+	}
+}
+
+func TestBag_DifferentSeedsDiffer(t *testing.T) {
+	a := NewSeededBag(1)
+	b := NewSeededBag(2)
+
+	for range 70 {
+		if a.Next() != b.Next() {
+			return
+		}
+	}
+	t.Error("different seeds produced the same 70 pieces")
+}
+
+func TestBag_WithSource(t *testing.T) {
+	a := NewBagWithSource(rand.NewPCG(7, 7))
+	b := NewSeededBag(7)
+
+	if got, want := a.Peek(14), b.Peek(14); !slices.Equal(got, want) {
+		t.Errorf("NewBagWithSource: %v, NewSeededBag: %v", got, want)
+	}
+}
+
+func TestBag_RestoreRewindsSequence(t *testing.T) {
+	b := NewSeededBag(3)
+	b.Next()
+
+	saved, err := b.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []PieceType
+	for range 20 {
+		want = append(want, b.Next())
+	}
+
+	if err := b.Restore(saved); err != nil {
+		t.Fatal(err)
+	}
+	var got []PieceType
+	for range 20 {
+		got = append(got, b.Next())
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("restored bag diverged: got %v, want %v", got, want)
+	}
+}
@@ -1,5 +1,7 @@
 package core
 
+import "math/rand/v2"
+
 const (
 	Space       = 2
 	BoardWidth  = 10
@@ -44,6 +46,15 @@ func (b *Board) Clear() {
 	}
 }
 
+func (b *Board) IsEmpty() bool {
+	for _, c := range b.Cells {
+		if c != PieceNone {
+			return false
+		}
+	}
+	return true
+}
+
 func (b *Board) HasCollision(p Piece) bool {
 	minos := GetRotatedMinos(p.Type, p.Rotation)
 
@@ -128,6 +139,93 @@ func (b *Board) ClearLines() int32 {
 	return linesCleared
 }
 
+// AddGarbage pushes the stack up by rows and fills the bottom rows with
+// garbage, leaving hole empty in each of them. It reports false if any cell
+// was pushed off the top of the board.
+func (b *Board) AddGarbage(rows, hole int) bool {
+	if rows <= 0 {
+		return true
+	}
+	rows = min(rows, BoardHeight)
+
+	ok := true
+	for y := 0; y < rows; y++ {
+		for x := 0; x < BoardWidth; x++ {
+			if b.Get(Point{X: x, Y: y}) != PieceNone {
+				ok = false
+			}
+		}
+	}
+
+	copy(b.Cells, b.Cells[rows*BoardWidth:])
+
+	for y := BoardHeight - rows; y < BoardHeight; y++ {
+		for x := 0; x < BoardWidth; x++ {
+			t := PieceGarbage
+			if x == hole {
+				t = PieceNone
+			}
+			b.Set(Point{X: x, Y: y}, t)
+		}
+	}
+
+	return ok
+}
+
+// AddCheese pushes the stack up by rows of garbage with holes picked by rng.
+// Clean cheese has every new row's hole in the same column; messy cheese moves
+// the hole to a different column on every row. It reports false if any cell
+// was pushed off the top of the board.
+func (b *Board) AddCheese(rows int, messy bool, rng *rand.Rand) bool {
+	if !messy {
+		return b.AddGarbage(rows, rng.IntN(BoardWidth))
+	}
+
+	ok := true
+	hole := b.hole(BoardHeight - 1)
+	for range rows {
+		next := rng.IntN(BoardWidth - 1)
+		if hole >= 0 && next >= hole {
+			next++
+		}
+		hole = next
+		if !b.AddGarbage(1, hole) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// hole returns the only empty column of row y, or -1 if the row does not have
+// exactly one.
+func (b *Board) hole(y int) int {
+	hole := -1
+	for x := range BoardWidth {
+		if b.Get(Point{X: x, Y: y}) != PieceNone {
+			continue
+		}
+		if hole >= 0 {
+			return -1
+		}
+		hole = x
+	}
+	return hole
+}
+
+// GarbageRows counts the rows holding at least one garbage cell.
+func (b *Board) GarbageRows() int {
+	rows := 0
+	for y := range BoardHeight {
+		for x := range BoardWidth {
+			if b.Get(Point{X: x, Y: y}) == PieceGarbage {
+				rows++
+				break
+			}
+		}
+	}
+	return rows
+}
+
 func (b *Board) ToBytes() []byte {
 	out := make([]byte, len(b.Cells))
 	for i, v := range b.Cells {
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestBoard_AddGarbage_PushesStackUp(t *testing.T) {
+	b := NewBoard()
+	b.Set(Point{X: 2, Y: BoardHeight - 1}, PieceT)
+
+	if !b.AddGarbage(2, 5) {
+		t.Fatal("unexpected top out")
+	}
+
+	if b.Get(Point{X: 2, Y: BoardHeight - 3}) != PieceT {
+		t.Error("stack must move up by the number of garbage rows")
+	}
+
+	for y := BoardHeight - 2; y < BoardHeight; y++ {
+		for x := 0; x < BoardWidth; x++ {
+			want := PieceGarbage
+			if x == 5 {
+				want = PieceNone
+			}
+			if got := b.Get(Point{X: x, Y: y}); got != want {
+				t.Errorf("(%d, %d): got %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestBoard_AddGarbage_TopOut(t *testing.T) {
+	b := NewBoard()
+	b.Set(Point{X: 0, Y: 1}, PieceT)
+
+	if b.AddGarbage(2, 0) {
+		t.Error("expected top out when the stack is pushed off the board")
+	}
+}
+
+func TestBoard_IsEmpty(t *testing.T) {
+	b := NewBoard()
+	if !b.IsEmpty() {
+		t.Error("new board must be empty")
+	}
+
+	b.Set(Point{X: 3, Y: 3}, PieceI)
+	if b.IsEmpty() {
+		t.Error("board with a cell must not be empty")
+	}
+}
+
+func TestBoard_AddCheese(t *testing.T) {
+	clean := NewBoard()
+	if !clean.AddCheese(5, false, rand.New(rand.NewPCG(1, 1))) {
+		t.Fatal("unexpected top out")
+	}
+	hole := clean.hole(BoardHeight - 1)
+	for y := BoardHeight - 5; y < BoardHeight; y++ {
+		if got := clean.hole(y); got != hole || got < 0 {
+			t.Errorf("clean cheese row %d: hole %d, want %d", y, got, hole)
+		}
+	}
+
+	messy := NewBoard()
+	messy.AddCheese(8, true, rand.New(rand.NewPCG(1, 1)))
+	for y := BoardHeight - 8; y < BoardHeight-1; y++ {
+		if above, below := messy.hole(y), messy.hole(y+1); above < 0 || above == below {
+			t.Errorf("messy cheese rows %d and %d: holes %d and %d", y, y+1, above, below)
+		}
+	}
+	if rows := messy.GarbageRows(); rows != 8 {
+		t.Errorf("expected 8 garbage rows, got %d", rows)
+	}
+}
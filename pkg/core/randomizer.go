@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+type Randomizer interface {
+	Next() PieceType
+	Peek(n int) []PieceType
+}
+
+type RandomizerKind string
+
+const (
+	Randomizer7Bag   RandomizerKind = "7bag"
+	Randomizer14Bag  RandomizerKind = "14bag"
+	RandomizerRandom RandomizerKind = "random"
+	RandomizerNES    RandomizerKind = "nes"
+	RandomizerTGM    RandomizerKind = "tgm"
+)
+
+var randomizerKinds = []RandomizerKind{
+	Randomizer7Bag,
+	Randomizer14Bag,
+	RandomizerRandom,
+	RandomizerNES,
+	RandomizerTGM,
+}
+
+// memorylessAlias is the classic name of the pure random generator.
+const memorylessAlias = "memoryless"
+
+// ParseRandomizerKind validates a randomizer name. An empty name selects the
+// 7-bag, and "memoryless" selects RandomizerRandom.
+func ParseRandomizerKind(name string) (RandomizerKind, error) {
+	switch name {
+	case "":
+		return Randomizer7Bag, nil
+	case memorylessAlias:
+		return RandomizerRandom, nil
+	}
+
+	kind := RandomizerKind(name)
+	if !slices.Contains(randomizerKinds, kind) {
+		return "", fmt.Errorf("unknown randomizer %q", name)
+	}
+	return kind, nil
+}
+
+// NewRandomizer creates a seeded randomizer of the given kind. Unknown kinds
+// fall back to the 7-bag.
+func NewRandomizer(kind RandomizerKind, seed uint64) Randomizer {
+	src := rand.NewPCG(seed, seed)
+
+	switch kind {
+	case Randomizer14Bag:
+		return NewBag14WithSource(src)
+	case RandomizerRandom:
+		return NewRandomWithSource(src)
+	case RandomizerNES:
+		return NewNESWithSource(src)
+	case RandomizerTGM:
+		return NewTGMWithSource(src)
+	default:
+		return NewBagWithSource(src)
+	}
+}
+
+// lookahead turns a piece generator into a Randomizer by buffering generated
+// pieces for Peek.
+type lookahead struct {
+	buf      []PieceType
+	generate func() PieceType
+}
+
+func (l *lookahead) fill(n int) {
+	for len(l.buf) < n {
+		l.buf = append(l.buf, l.generate())
+	}
+}
+
+func (l *lookahead) Next() PieceType {
+	l.fill(1)
+	p := l.buf[0]
+	l.buf = l.buf[1:]
+	return p
+}
+
+func (l *lookahead) Peek(n int) []PieceType {
+	l.fill(n)
+	out := make([]PieceType, n)
+	copy(out, l.buf[:n])
+	return out
+}
+
+// Random picks every piece independently with equal probability. It is also
+// known as the classic memoryless mode.
+type Random struct {
+	lookahead
+	rng *rand.Rand
+}
+
+func NewRandomWithSource(src rand.Source) *Random {
+	r := &Random{rng: rand.New(src)}
+	r.generate = r.roll
+	return r
+}
+
+func (r *Random) roll() PieceType {
+	return allPieceTypes[r.rng.IntN(len(allPieceTypes))]
+}
+
+// NES rolls one of eight values and rerolls once if it got the extra value or
+// repeated the previous piece.
+type NES struct {
+	lookahead
+	rng  *rand.Rand
+	prev PieceType
+}
+
+func NewNESWithSource(src rand.Source) *NES {
+	r := &NES{rng: rand.New(src)}
+	r.generate = r.roll
+	return r
+}
+
+func (r *NES) roll() PieceType {
+	idx := r.rng.IntN(len(allPieceTypes) + 1)
+	if idx == len(allPieceTypes) || allPieceTypes[idx] == r.prev {
+		idx = r.rng.IntN(len(allPieceTypes))
+	}
+
+	r.prev = allPieceTypes[idx]
+	return r.prev
+}
+
+const tgmRolls = 6
+
+var tgmFirstPieces = [4]PieceType{PieceI, PieceJ, PieceL, PieceT}
+
+// TGM keeps a history of the last four pieces and rolls up to six times for a
+// piece that is not in it. The first piece is never S, Z or O.
+type TGM struct {
+	lookahead
+	rng     *rand.Rand
+	history [4]PieceType
+	first   bool
+}
+
+func NewTGMWithSource(src rand.Source) *TGM {
+	r := &TGM{
+		rng:     rand.New(src),
+		history: [4]PieceType{PieceS, PieceZ, PieceS, PieceZ},
+		first:   true,
+	}
+	r.generate = r.roll
+	return r
+}
+
+func (r *TGM) roll() PieceType {
+	var p PieceType
+	if r.first {
+		r.first = false
+		p = tgmFirstPieces[r.rng.IntN(len(tgmFirstPieces))]
+	} else {
+		for range tgmRolls {
+			p = allPieceTypes[r.rng.IntN(len(allPieceTypes))]
+			if !slices.Contains(r.history[:], p) {
+				break
+			}
+		}
+	}
+
+	copy(r.history[:], r.history[1:])
+	r.history[len(r.history)-1] = p
+	return p
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestRandomizer_AllKindsAreDeterministic(t *testing.T) {
+	for _, kind := range randomizerKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			a := NewRandomizer(kind, 99)
+			b := NewRandomizer(kind, 99)
+
+			for i := range 200 {
+				if pa, pb := a.Next(), b.Next(); pa != pb {
+					t.Fatalf("step %d: %v != %v", i, pa, pb)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomizer_PeekDoesNotConsume(t *testing.T) {
+	for _, kind := range randomizerKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			r := NewRandomizer(kind, 5)
+
+			peeked := r.Peek(5)
+			for i, want := range peeked {
+				if got := r.Next(); got != want {
+					t.Errorf("step %d: Peek=%v, Next=%v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomizer_OnlyRealPieces(t *testing.T) {
+	for _, kind := range randomizerKinds {
+		t.Run(string(kind), func(t *testing.T) {
+			r := NewRandomizer(kind, 11)
+			for range 500 {
+				if p := r.Next(); !slices.Contains(allPieceTypes[:], p) {
+					t.Fatalf("unexpected piece %v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestBag14_TwoOfEachPiece(t *testing.T) {
+	r := NewRandomizer(Randomizer14Bag, 3)
+
+	for bag := range 50 {
+		counts := make(map[PieceType]int)
+		for range 14 {
+			counts[r.Next()]++
+		}
+		for _, pt := range allPieceTypes {
+			if counts[pt] != 2 {
+				t.Errorf("bag %d: piece %v appeared %d times", bag, pt, counts[pt])
+			}
+		}
+	}
+}
+
+func TestTGM_FirstPieceIsNotSZO(t *testing.T) {
+	for seed := range uint64(200) {
+		first := NewRandomizer(RandomizerTGM, seed).Next()
+		if first == PieceS || first == PieceZ || first == PieceO {
+			t.Fatalf("seed %d: first piece %v", seed, first)
+		}
+	}
+}
+
+func TestParseRandomizerKind(t *testing.T) {
+	if kind, err := ParseRandomizerKind(""); err != nil || kind != Randomizer7Bag {
+		t.Errorf("empty name: got %q, %v", kind, err)
+	}
+	if kind, err := ParseRandomizerKind("tgm"); err != nil || kind != RandomizerTGM {
+		t.Errorf("tgm: got %q, %v", kind, err)
+	}
+	if kind, err := ParseRandomizerKind("memoryless"); err != nil || kind != RandomizerRandom {
+		t.Errorf("memoryless must select the random kind, got %q, %v", kind, err)
+	}
+	if _, err := ParseRandomizerKind("dice"); err == nil {
+		t.Error("expected error for unknown randomizer")
+	}
+}
+
+func TestRandomizer_KindsDiffer(t *testing.T) {
+	seen := make(map[string]RandomizerKind)
+	for _, kind := range randomizerKinds {
+		r := NewRandomizer(kind, 7)
+		sequence := fmt.Sprint(r.Peek(50))
+
+		if other, ok := seen[sequence]; ok {
+			t.Errorf("%s and %s produce the same sequence", kind, other)
+		}
+		seen[sequence] = kind
+	}
+}
@@ -56,11 +56,18 @@ func getKickTable(t PieceType) *[8]KickData {
 }
 
 func TryRotate(b *Board, p Piece, direction int) (Piece, bool) {
+	rotated, _, ok := TryRotateKick(b, p, direction)
+	return rotated, ok
+}
+
+// TryRotateKick works like TryRotate and also reports the index of the kick
+// test that succeeded (0 means no kick was needed).
+func TryRotateKick(b *Board, p Piece, direction int) (Piece, int, bool) {
 	table := getKickTable(p.Type)
 	if table == nil {
 		rotated := p
 		rotated.Rotation = (p.Rotation + direction%4 + 4) % 4
-		return rotated, true
+		return rotated, 0, true
 	}
 
 	newRotation := (p.Rotation + direction%4 + 4) % 4
@@ -68,14 +75,14 @@ func TryRotate(b *Board, p Piece, direction int) (Piece, bool) {
 	idx := kickIndex(p.Rotation, direction)
 	kicks := table[idx]
 
-	for _, kick := range kicks {
+	for i, kick := range kicks {
 		candidate := p
 		candidate.Rotation = newRotation
 		candidate.Position = p.Position.Add(kick)
 
 		if !b.HasCollision(candidate) {
-			return candidate, true
+			return candidate, i, true
 		}
 	}
-	return p, false
+	return p, 0, false
 }
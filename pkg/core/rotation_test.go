@@ -133,3 +133,21 @@ func TestTryRotate_TSpin(t *testing.T) {
 		t.Error("T-Spin failed")
 	}
 }
+
+func TestTryRotateKick_ReportsKickIndex(t *testing.T) {
+	b := NewBoard()
+	p := Piece{Type: PieceI, Position: Point{X: 1, Y: 5}, Rotation: 1}
+
+	got, kick, ok := TryRotateKick(b, p, RotateCW)
+	if !ok {
+		t.Fatal("I-piece on left wall must be rotated kick")
+	}
+	if kick == 0 {
+		t.Error("expected a wall kick, got kick index 0")
+	}
+
+	expected := p.Position.Add(kicksI[kickIndex(1, RotateCW)][kick])
+	if got.Position != expected {
+		t.Errorf("position: got %v, want %v", got.Position, expected)
+	}
+}
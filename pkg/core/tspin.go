@@ -0,0 +1,52 @@
+package core
+
+type TSpin int
+
+const (
+	TSpinNone TSpin = iota
+	TSpinMini
+	TSpinFull
+)
+
+// tstKick is the index of the last SRS kick test. A T-spin that needed it is
+// always full, even if only one front corner is occupied.
+const tstKick = 4
+
+// DetectTSpin classifies a T piece about to lock using the 3-corner rule.
+// lastRotate must be true only if the last successful action on the piece was
+// a rotation, and kick is the kick index that rotation used.
+func DetectTSpin(b *Board, p Piece, lastRotate bool, kick int) TSpin {
+	if p.Type != PieceT || !lastRotate {
+		return TSpinNone
+	}
+
+	front := Point{X: 0, Y: 1}
+	for r := 0; r < (p.Rotation%4+4)%4; r++ {
+		front = front.RotateCW()
+	}
+	side := Point{X: front.Y, Y: front.X}
+	back := Point{X: -front.X, Y: -front.Y}
+	otherSide := Point{X: -side.X, Y: -side.Y}
+
+	frontCorners := b.countBlocked(p.Position, front.Add(side), front.Add(otherSide))
+	backCorners := b.countBlocked(p.Position, back.Add(side), back.Add(otherSide))
+
+	if frontCorners+backCorners < 3 {
+		return TSpinNone
+	}
+	if frontCorners == 2 || kick == tstKick {
+		return TSpinFull
+	}
+	return TSpinMini
+}
+
+func (b *Board) countBlocked(origin Point, offsets ...Point) int {
+	n := 0
+	for _, o := range offsets {
+		abs := origin.Add(o)
+		if !b.IsInside(abs) || b.Get(abs) != PieceNone {
+			n++
+		}
+	}
+	return n
+}
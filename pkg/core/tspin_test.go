@@ -0,0 +1,97 @@
+package core
+
+import "testing"
+
+// buildTSDBoard builds a T-spin double slot centred on (4, 20) with an
+// overhang on the left.
+func buildTSDBoard() *Board {
+	b := NewBoard()
+
+	type cellFill struct{ x, y int }
+	holes := map[cellFill]bool{
+		{3, 20}: true,
+		{4, 20}: true,
+		{5, 20}: true,
+		{4, 21}: true,
+	}
+
+	for y := 20; y <= 21; y++ {
+		for x := 0; x < BoardWidth; x++ {
+			if !holes[cellFill{x, y}] {
+				b.Set(Point{X: x, Y: y}, PieceT)
+			}
+		}
+	}
+	b.Set(Point{X: 3, Y: 19}, PieceT)
+
+	return b
+}
+
+func TestDetectTSpin_Full(t *testing.T) {
+	b := buildTSDBoard()
+	p := Piece{Type: PieceT, Position: Point{X: 4, Y: 20}, Rotation: 3}
+
+	rotated, kick, ok := TryRotateKick(b, p, RotateCW)
+	if !ok {
+		t.Fatal("T-Spin failed")
+	}
+
+	if got := DetectTSpin(b, rotated, true, kick); got != TSpinFull {
+		t.Errorf("got %v, want TSpinFull", got)
+	}
+
+	b.LockPiece(rotated)
+	if lines := b.ClearLines(); lines != 2 {
+		t.Errorf("T-spin double cleared %d lines", lines)
+	}
+}
+
+func TestDetectTSpin_Mini(t *testing.T) {
+	b := buildTSDBoard()
+	p := Piece{Type: PieceT, Position: Point{X: 4, Y: 20}, Rotation: 3}
+
+	rotated, kick, ok := TryRotateKick(b, p, RotateCCW)
+	if !ok {
+		t.Fatal("T-Spin failed")
+	}
+
+	if got := DetectTSpin(b, rotated, true, kick); got != TSpinMini {
+		t.Errorf("got %v, want TSpinMini", got)
+	}
+}
+
+func TestDetectTSpin_LastKickUpgradesMini(t *testing.T) {
+	b := buildTSDBoard()
+	p := Piece{Type: PieceT, Position: Point{X: 4, Y: 20}, Rotation: 2}
+
+	if got := DetectTSpin(b, p, true, tstKick); got != TSpinFull {
+		t.Errorf("got %v, want TSpinFull", got)
+	}
+}
+
+func TestDetectTSpin_RequiresRotation(t *testing.T) {
+	b := buildTSDBoard()
+	p := Piece{Type: PieceT, Position: Point{X: 4, Y: 20}, Rotation: 0}
+
+	if got := DetectTSpin(b, p, false, 0); got != TSpinNone {
+		t.Errorf("got %v, want TSpinNone", got)
+	}
+}
+
+func TestDetectTSpin_OnlyTPiece(t *testing.T) {
+	b := buildTSDBoard()
+	p := Piece{Type: PieceJ, Position: Point{X: 4, Y: 20}, Rotation: 0}
+
+	if got := DetectTSpin(b, p, true, 0); got != TSpinNone {
+		t.Errorf("got %v, want TSpinNone", got)
+	}
+}
+
+func TestDetectTSpin_OpenCorners(t *testing.T) {
+	b := NewBoard()
+	p := Piece{Type: PieceT, Position: Point{X: 4, Y: 10}, Rotation: 0}
+
+	if got := DetectTSpin(b, p, true, 0); got != TSpinNone {
+		t.Errorf("got %v, want TSpinNone", got)
+	}
+}
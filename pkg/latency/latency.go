@@ -0,0 +1,64 @@
+package latency
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"sync"
+	"time"
+)
+
+// Interval is how often clients ping the server. Servers disconnect streams
+// that stay silent for much longer than this.
+const Interval = time.Second
+
+// Pinger builds PingRequests and measures the round trip time from the
+// matching PongResponses. It is safe for concurrent use, so pings can be sent
+// and pongs received on different goroutines.
+type Pinger struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	rtt             time.Duration
+	serverTimestamp int64
+	pongReceived    time.Time
+}
+
+func NewPinger() *Pinger {
+	return &Pinger{now: time.Now}
+}
+
+// Ping returns the next ping. It echoes the last pong's server timestamp so
+// the server can measure the round trip as well.
+func (p *Pinger) Ping() *pb.PingRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	ping := &pb.PingRequest{Timestamp: now.UnixNano()}
+	if p.serverTimestamp != 0 {
+		ping.ServerTimestamp = p.serverTimestamp
+		ping.EchoDelay = int64(now.Sub(p.pongReceived))
+		p.serverTimestamp = 0
+	}
+	return ping
+}
+
+func (p *Pinger) Pong(pong *pb.PongResponse) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if rtt := now.Sub(time.Unix(0, pong.Timestamp)); rtt >= 0 {
+		p.rtt = rtt
+	}
+	p.serverTimestamp = pong.ServerTimestamp
+	p.pongReceived = now
+}
+
+// RTT returns the last measured round trip time, or zero before the first
+// pong.
+func (p *Pinger) RTT() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.rtt
+}
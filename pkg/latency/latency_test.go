@@ -0,0 +1,34 @@
+package latency
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"testing"
+	"time"
+)
+
+func TestPinger_MeasuresRoundTrip(t *testing.T) {
+	now := time.Unix(100, 0)
+	p := NewPinger()
+	p.now = func() time.Time { return now }
+
+	ping := p.Ping()
+	if ping.ServerTimestamp != 0 {
+		t.Error("first ping has no pong to echo")
+	}
+
+	now = now.Add(40 * time.Millisecond)
+	p.Pong(&pb.PongResponse{Timestamp: ping.Timestamp, ServerTimestamp: 12345})
+
+	if rtt := p.RTT(); rtt != 40*time.Millisecond {
+		t.Errorf("expected 40ms, got %v", rtt)
+	}
+
+	now = now.Add(time.Second)
+	next := p.Ping()
+	if next.ServerTimestamp != 12345 || time.Duration(next.EchoDelay) != time.Second {
+		t.Errorf("expected the pong echoed after 1s, got %d after %v", next.ServerTimestamp, time.Duration(next.EchoDelay))
+	}
+	if again := p.Ping(); again.ServerTimestamp != 0 {
+		t.Error("a pong must only be echoed once")
+	}
+}
@@ -0,0 +1,137 @@
+package predict
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/core"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type pendingInput struct {
+	seq   uint64
+	input pb.InputType
+}
+
+// Predictor numbers client inputs and, when enabled, applies movement and
+// rotation locally on top of the last authoritative state so the piece
+// responds before the server round trip completes.
+//
+// Inputs the client cannot simulate without the server's randomizer, such as
+// hard drop and hold, are sent but not predicted; prediction stops at them
+// until the server acknowledges them.
+type Predictor struct {
+	enabled bool
+	nextSeq uint64
+
+	auth      *pb.StateUpdate
+	predicted *pb.StateUpdate
+	pending   []pendingInput
+}
+
+func New(enabled bool) *Predictor {
+	return &Predictor{enabled: enabled}
+}
+
+// Input assigns the next sequence id to input and returns it for the
+// InputRequest.
+func (p *Predictor) Input(input pb.InputType) uint64 {
+	p.nextSeq++
+	if p.enabled && p.auth != nil {
+		p.pending = append(p.pending, pendingInput{seq: p.nextSeq, input: input})
+		p.predict()
+	}
+	return p.nextSeq
+}
+
+// Reconcile replaces the prediction base with the server's state, drops the
+// inputs it already applied and replays the rest.
+func (p *Predictor) Reconcile(state *pb.StateUpdate) {
+	p.auth = state
+
+	acked := 0
+	for acked < len(p.pending) && p.pending[acked].seq <= state.LastSequenceId {
+		acked++
+	}
+	p.pending = p.pending[acked:]
+
+	p.predict()
+}
+
+// State returns the state to display: the authoritative state with pending
+// inputs applied.
+func (p *Predictor) State() *pb.StateUpdate {
+	if p.predicted != nil {
+		return p.predicted
+	}
+	return p.auth
+}
+
+// Pending returns the number of inputs waiting for acknowledgement.
+func (p *Predictor) Pending() int {
+	return len(p.pending)
+}
+
+func (p *Predictor) predict() {
+	p.predicted = nil
+	if len(p.pending) == 0 || p.auth == nil || p.auth.CurrentPiece == nil {
+		return
+	}
+
+	board := core.NewBoard()
+	for i := 0; i < len(p.auth.Grid) && i < len(board.Cells); i++ {
+		board.Cells[i] = core.PieceType(p.auth.Grid[i])
+	}
+
+	piece := core.Piece{
+		Type:     core.PieceType(p.auth.CurrentPiece.Type), //nolint:gosec // piece types are small enums
+		Position: core.Point{X: int(p.auth.CurrentPiece.X), Y: int(p.auth.CurrentPiece.Y)},
+		Rotation: int(p.auth.CurrentPiece.Rotation),
+	}
+	if piece.Type == core.PieceNone {
+		return
+	}
+
+	for _, in := range p.pending {
+		next, ok := step(board, piece, in.input)
+		if !ok {
+			break
+		}
+		piece = next
+	}
+
+	predicted := proto.Clone(p.auth).(*pb.StateUpdate)
+	predicted.CurrentPiece = &pb.Piece{
+		Type:     p.auth.CurrentPiece.Type,
+		X:        int32(piece.Position.X), //nolint:gosec // coordinates are small
+		Y:        int32(piece.Position.Y), //nolint:gosec // coordinates are small
+		Rotation: int32(piece.Rotation),   //nolint:gosec // coordinates are small
+	}
+	p.predicted = predicted
+}
+
+// step applies a single input to piece. It reports false for inputs that
+// cannot be predicted.
+func step(board *core.Board, piece core.Piece, input pb.InputType) (core.Piece, bool) {
+	next := piece
+	switch input {
+	case pb.InputType_INPUT_LEFT:
+		next.Position.X--
+	case pb.InputType_INPUT_RIGHT:
+		next.Position.X++
+	case pb.InputType_INPUT_SOFT_DROP:
+		next.Position.Y++
+	case pb.InputType_INPUT_ROTATE_CW:
+		next, _ = core.TryRotate(board, piece, core.RotateCW)
+		return next, true
+	case pb.InputType_INPUT_ROTATE_CCW:
+		next, _ = core.TryRotate(board, piece, core.RotateCCW)
+		return next, true
+	default:
+		return piece, false
+	}
+
+	if board.HasCollision(next) {
+		return piece, true
+	}
+	return next, true
+}
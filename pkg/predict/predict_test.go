@@ -0,0 +1,77 @@
+package predict
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/core"
+	"testing"
+)
+
+func newState(x, y int32, lastSeq uint64) *pb.StateUpdate {
+	return &pb.StateUpdate{
+		Grid:           make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece:   &pb.Piece{Type: pb.PieceType_PIECE_T, X: x, Y: y},
+		LastSequenceId: lastSeq,
+	}
+}
+
+func TestPredictor_AppliesInputsLocally(t *testing.T) {
+	p := New(true)
+	p.Reconcile(newState(4, 0, 0))
+
+	if seq := p.Input(pb.InputType_INPUT_LEFT); seq != 1 {
+		t.Fatalf("expected sequence 1, got %d", seq)
+	}
+	p.Input(pb.InputType_INPUT_LEFT)
+
+	if x := p.State().CurrentPiece.X; x != 2 {
+		t.Errorf("expected predicted X=2, got %d", x)
+	}
+}
+
+func TestPredictor_ReconcileReplaysUnacknowledgedInputs(t *testing.T) {
+	p := New(true)
+	p.Reconcile(newState(4, 0, 0))
+
+	p.Input(pb.InputType_INPUT_LEFT)
+	p.Input(pb.InputType_INPUT_LEFT)
+	p.Input(pb.InputType_INPUT_LEFT)
+
+	// The server applied the first input and gravity moved the piece down.
+	p.Reconcile(newState(3, 1, 1))
+
+	if p.Pending() != 2 {
+		t.Fatalf("expected 2 pending inputs, got %d", p.Pending())
+	}
+	if piece := p.State().CurrentPiece; piece.X != 1 || piece.Y != 1 {
+		t.Errorf("expected predicted piece at (1, 1), got (%d, %d)", piece.X, piece.Y)
+	}
+
+	p.Reconcile(newState(1, 1, 3))
+	if p.Pending() != 0 {
+		t.Errorf("expected no pending inputs, got %d", p.Pending())
+	}
+}
+
+func TestPredictor_StopsAtUnpredictableInput(t *testing.T) {
+	p := New(true)
+	p.Reconcile(newState(4, 0, 0))
+
+	p.Input(pb.InputType_INPUT_HARD_DROP)
+	p.Input(pb.InputType_INPUT_LEFT)
+
+	if x := p.State().CurrentPiece.X; x != 4 {
+		t.Errorf("inputs after a hard drop must wait for the server, got X=%d", x)
+	}
+}
+
+func TestPredictor_Disabled(t *testing.T) {
+	p := New(false)
+	state := newState(4, 0, 0)
+	p.Reconcile(state)
+
+	p.Input(pb.InputType_INPUT_LEFT)
+
+	if p.State() != state {
+		t.Error("disabled predictor must show the authoritative state")
+	}
+}
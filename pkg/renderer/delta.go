@@ -0,0 +1,66 @@
+package renderer
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNoBaseState is returned by ApplyDelta when the client has no state the
+// delta can be applied to. The client should send a ResyncRequest.
+var ErrNoBaseState = errors.New("delta does not match the current state")
+
+// ApplyDelta returns a copy of state with delta applied. state is left
+// untouched.
+func ApplyDelta(state *pb.StateUpdate, delta *pb.StateDelta) (*pb.StateUpdate, error) {
+	if state == nil {
+		return nil, ErrNoBaseState
+	}
+	for _, cell := range delta.Cells {
+		if int(cell.Index) >= len(state.Grid) {
+			return nil, ErrNoBaseState
+		}
+	}
+
+	next := proto.Clone(state).(*pb.StateUpdate)
+	next.TickId = delta.TickId
+	next.LastSequenceId = delta.LastSequenceId
+	if delta.PlayerId != "" {
+		next.PlayerId = delta.PlayerId
+	}
+
+	for _, cell := range delta.Cells {
+		next.Grid[cell.Index] = byte(cell.Type) //nolint:gosec // piece types are small enums
+	}
+
+	if delta.CurrentPiece != nil {
+		next.CurrentPiece = delta.CurrentPiece
+	}
+	if delta.NextPieces != nil {
+		next.NextPieces = delta.NextPieces.Pieces
+	}
+	if delta.HeldPiece != nil {
+		next.HeldPiece = *delta.HeldPiece
+	}
+	if delta.Score != nil {
+		next.Score = *delta.Score
+	}
+	if delta.Level != nil {
+		next.Level = *delta.Level
+	}
+	if delta.PendingGarbage != nil {
+		next.PendingGarbage = *delta.PendingGarbage
+	}
+	if delta.Lines != nil {
+		next.Lines = *delta.Lines
+	}
+	if delta.TimeRemainingMs != nil {
+		next.TimeRemainingMs = *delta.TimeRemainingMs
+	}
+	if delta.Paused != nil {
+		next.Paused = *delta.Paused
+	}
+
+	return next, nil
+}
@@ -0,0 +1,49 @@
+package renderer
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/core"
+	"errors"
+	"testing"
+)
+
+func TestApplyDelta_KeepsUnchangedFields(t *testing.T) {
+	state := &pb.StateUpdate{
+		Grid:         make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece: &pb.Piece{Type: pb.PieceType_PIECE_T, X: 4},
+		Score:        300,
+		Level:        2,
+	}
+	level := int32(3)
+
+	next, err := ApplyDelta(state, &pb.StateDelta{
+		TickId: 10,
+		Cells:  []*pb.CellChange{{Index: 5, Type: pb.PieceType_PIECE_I}},
+		Level:  &level,
+	})
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	if next.Score != 300 || next.CurrentPiece.X != 4 {
+		t.Error("fields missing from the delta must be kept")
+	}
+	if next.Level != 3 || next.TickId != 10 || next.Grid[5] != byte(core.PieceI) {
+		t.Errorf("delta not applied: %v", next)
+	}
+	if state.Grid[5] != 0 || state.Level != 2 {
+		t.Error("the base state must not be modified")
+	}
+}
+
+func TestApplyDelta_RequiresBase(t *testing.T) {
+	if _, err := ApplyDelta(nil, &pb.StateDelta{}); !errors.Is(err, ErrNoBaseState) {
+		t.Errorf("expected ErrNoBaseState without a base, got %v", err)
+	}
+
+	state := &pb.StateUpdate{Grid: make([]byte, 4)}
+	delta := &pb.StateDelta{Cells: []*pb.CellChange{{Index: 10}}}
+	if _, err := ApplyDelta(state, delta); !errors.Is(err, ErrNoBaseState) {
+		t.Errorf("expected ErrNoBaseState for an out of range cell, got %v", err)
+	}
+}
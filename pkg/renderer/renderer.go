@@ -4,6 +4,7 @@ import (
 	pb "GoTetrisOnline/api/proto/game/v1"
 	"GoTetrisOnline/pkg/core"
 	"image/color"
+	"time"
 )
 
 type CellType int
@@ -23,18 +24,39 @@ type Cell struct {
 type GameView struct {
 	Board     [][]Cell
 	NextPiece core.PieceType
+	HeldPiece core.PieceType
+	Garbage   int32
 	Score     int32
 	Level     int32
 	Width     int
 	Height    int
+
+	Mode     string
+	Lines    int32
+	LineGoal int32
+	Elapsed  time.Duration
+	// TimeLimit is the game time at which a timed mode ends, zero if the
+	// mode has none.
+	TimeLimit time.Duration
+	Paused    bool
 }
 
 func StateToView(state *pb.StateUpdate) *GameView {
 	view := &GameView{
-		Score:  state.Score,
-		Level:  state.Level,
-		Width:  core.BoardWidth,
-		Height: core.BoardHeight - core.Space,
+		Score:   state.Score,
+		Level:   state.Level,
+		Garbage: state.PendingGarbage,
+		Width:   core.BoardWidth,
+		Height:  core.BoardHeight - core.Space,
+
+		Mode:     state.Mode,
+		Lines:    state.Lines,
+		LineGoal: state.LineGoal,
+		Elapsed:  ElapsedTime(state.TickId),
+		Paused:   state.Paused,
+	}
+	if state.Mode == ModeUltra {
+		view.TimeLimit = view.Elapsed + time.Duration(state.TimeRemainingMs)*time.Millisecond
 	}
 
 	view.Board = make([][]Cell, view.Height)
@@ -52,6 +74,7 @@ func StateToView(state *pb.StateUpdate) *GameView {
 	}
 
 	minos := core.GetRotatedMinos(currentPiece.Type, currentPiece.Rotation)
+	ghost := ghostPiece(state.Grid, currentPiece)
 
 	for y := core.Space; y < core.BoardHeight; y++ {
 		for x := 0; x < core.BoardWidth; x++ {
@@ -64,6 +87,21 @@ func StateToView(state *pb.StateUpdate) *GameView {
 				cell.PieceType = core.PieceType(state.Grid[idx]) //nolint:gosec
 			}
 
+			for _, mino := range minos {
+				if cell.Type != CellEmpty {
+					break
+				}
+
+				absX := ghost.Position.X + mino.X
+				absY := ghost.Position.Y + mino.Y
+
+				if absX == x && absY == y {
+					cell.Type = CellGhost
+					cell.PieceType = currentPiece.Type
+					break
+				}
+			}
+
 			for _, mino := range minos {
 				absX := currentPiece.Position.X + mino.X
 				absY := currentPiece.Position.Y + mino.Y
@@ -83,9 +121,37 @@ func StateToView(state *pb.StateUpdate) *GameView {
 		view.NextPiece = core.PieceType(state.NextPieces[0]) //nolint:gosec
 	}
 
+	view.HeldPiece = core.PieceType(state.HeldPiece) //nolint:gosec
+
 	return view
 }
 
+func ghostPiece(grid []byte, p core.Piece) core.Piece {
+	board := core.NewBoard()
+	for i := 0; i < len(grid) && i < len(board.Cells); i++ {
+		board.Cells[i] = core.PieceType(grid[i])
+	}
+
+	if p.Type == core.PieceNone || board.HasCollision(p) {
+		return p
+	}
+
+	for {
+		next := p
+		next.Position.Y++
+
+		if board.HasCollision(next) {
+			return p
+		}
+		p = next
+	}
+}
+
+func GetGhostColor(t core.PieceType) color.RGBA {
+	c := GetPieceColor(t)
+	return color.RGBA{c.R / 3, c.G / 3, c.B / 3, 255}
+}
+
 func GetPieceColor(t core.PieceType) color.RGBA {
 	switch t {
 	case core.PieceI:
@@ -0,0 +1,79 @@
+package renderer
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/core"
+	"testing"
+)
+
+func countCells(view *GameView, cellType CellType) int {
+	n := 0
+	for _, row := range view.Board {
+		for _, cell := range row {
+			if cell.Type == cellType {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestStateToView_GhostOnFloor(t *testing.T) {
+	state := &pb.StateUpdate{
+		Grid:         make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece: &pb.Piece{Type: pb.PieceType_PIECE_O, X: 4, Y: 2},
+	}
+
+	view := StateToView(state)
+
+	if got := countCells(view, CellGhost); got != 4 {
+		t.Fatalf("expected 4 ghost cells, got %d", got)
+	}
+
+	bottom := view.Height - 1
+	for _, x := range []int{4, 5} {
+		if view.Board[bottom][x].Type != CellGhost {
+			t.Errorf("expected ghost at (%d, %d), got %v", x, bottom, view.Board[bottom][x].Type)
+		}
+		if view.Board[bottom][x].PieceType != core.PieceO {
+			t.Errorf("ghost must keep the piece type, got %v", view.Board[bottom][x].PieceType)
+		}
+	}
+}
+
+func TestStateToView_GhostStopsOnStack(t *testing.T) {
+	grid := make([]byte, core.BoardWidth*core.BoardHeight)
+	for x := 0; x < core.BoardWidth; x++ {
+		grid[(core.BoardHeight-1)*core.BoardWidth+x] = byte(core.PieceGarbage)
+	}
+
+	state := &pb.StateUpdate{
+		Grid:         grid,
+		CurrentPiece: &pb.Piece{Type: pb.PieceType_PIECE_O, X: 4, Y: 2},
+	}
+
+	view := StateToView(state)
+
+	if got := countCells(view, CellFixed); got != core.BoardWidth {
+		t.Errorf("ghost must not cover fixed cells: %d fixed cells left", got)
+	}
+	if view.Board[view.Height-2][4].Type != CellGhost {
+		t.Errorf("expected ghost resting on the stack")
+	}
+}
+
+func TestStateToView_GhostHiddenUnderPiece(t *testing.T) {
+	state := &pb.StateUpdate{
+		Grid:         make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece: &pb.Piece{Type: pb.PieceType_PIECE_O, X: 4, Y: core.BoardHeight - 2},
+	}
+
+	view := StateToView(state)
+
+	if got := countCells(view, CellGhost); got != 0 {
+		t.Errorf("expected no ghost when piece is on the floor, got %d", got)
+	}
+	if got := countCells(view, CellPiece); got != 4 {
+		t.Errorf("expected 4 piece cells, got %d", got)
+	}
+}
@@ -0,0 +1,170 @@
+// Package term draws game views as styled text for terminal clients.
+package term
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/pkg/renderer"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	colorI     = lipgloss.NewStyle().Foreground(lipgloss.Color("51")).Bold(true)  // Cyan
+	colorO     = lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true) // Yellow
+	colorT     = lipgloss.NewStyle().Foreground(lipgloss.Color("129")).Bold(true) // Purple
+	colorS     = lipgloss.NewStyle().Foreground(lipgloss.Color("46")).Bold(true)  // Green
+	colorZ     = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // Red
+	colorJ     = lipgloss.NewStyle().Foreground(lipgloss.Color("21")).Bold(true)  // Blue
+	colorL     = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true) // Orange
+	colorGray  = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))            // Dark gray
+	colorPiece = lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Bold(true) // White (current piece)
+	colorMeter = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))            // Red (incoming garbage)
+
+	// BoxStyle frames the board and sidebar panels.
+	BoxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1)
+
+	// MeterStyle frames the garbage meter.
+	MeterStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63"))
+)
+
+// Board draws the playfield of view, two columns per cell.
+func Board(view *renderer.GameView) string {
+	var b strings.Builder
+
+	for y := 0; y < view.Height; y++ {
+		for x := 0; x < view.Width; x++ {
+			cell := view.Board[y][x]
+			var char string
+
+			switch cell.Type {
+			case renderer.CellPiece:
+				char = colorPiece.Render("██")
+			case renderer.CellFixed:
+				char = PieceStyle(cell.PieceType).Render("██")
+			case renderer.CellGhost:
+				char = PieceStyle(cell.PieceType).Faint(true).Render("▒▒")
+			default:
+				char = colorGray.Render("░░")
+			}
+
+			b.WriteString(char)
+		}
+
+		if y < view.Height-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// GarbageMeter draws a column as tall as the board that fills up from the
+// bottom with the incoming garbage.
+func GarbageMeter(view *renderer.GameView) string {
+	var b strings.Builder
+
+	for y := 0; y < view.Height; y++ {
+		if view.Height-y <= int(view.Garbage) {
+			b.WriteString(colorMeter.Render("█"))
+		} else {
+			b.WriteString(" ")
+		}
+
+		if y < view.Height-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// Piece draws t centred in a 4x4 grid, as shown in the next and hold boxes.
+func Piece(t core.PieceType) string {
+	var b strings.Builder
+	minos := core.GetRotatedMinos(t, 0)
+
+	minX, maxX := 0, 0
+	minY, maxY := 0, 0
+	for i, m := range minos {
+		if i == 0 {
+			minX, maxX = m.X, m.X
+			minY, maxY = m.Y, m.Y
+		} else {
+			if m.X < minX {
+				minX = m.X
+			}
+			if m.X > maxX {
+				maxX = m.X
+			}
+			if m.Y < minY {
+				minY = m.Y
+			}
+			if m.Y > maxY {
+				maxY = m.Y
+			}
+		}
+	}
+
+	width := maxX - minX + 1
+	height := maxY - minY + 1
+	gridSize := 4
+	offsetX := (gridSize - width) / 2
+	offsetY := (gridSize - height) / 2
+
+	grid := make([][]bool, gridSize)
+	for i := range grid {
+		grid[i] = make([]bool, gridSize)
+	}
+
+	for _, m := range minos {
+		x := m.X - minX + offsetX
+		y := m.Y - minY + offsetY
+		if x >= 0 && x < gridSize && y >= 0 && y < gridSize {
+			grid[y][x] = true
+		}
+	}
+
+	style := PieceStyle(t)
+	for y := 0; y < gridSize; y++ {
+		for x := 0; x < gridSize; x++ {
+			if grid[y][x] {
+				b.WriteString(style.Render("██"))
+			} else {
+				b.WriteString(colorGray.Render("░░"))
+			}
+		}
+		if y < gridSize-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// PieceStyle returns the colour of a piece type.
+func PieceStyle(t core.PieceType) lipgloss.Style {
+	switch t {
+	case core.PieceI:
+		return colorI
+	case core.PieceO:
+		return colorO
+	case core.PieceT:
+		return colorT
+	case core.PieceS:
+		return colorS
+	case core.PieceZ:
+		return colorZ
+	case core.PieceJ:
+		return colorJ
+	case core.PieceL:
+		return colorL
+	default:
+		return colorGray
+	}
+}
@@ -0,0 +1,126 @@
+package renderer
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// FramesPerSecond is the engine's frame rate. StateUpdate.tick_id counts
+	// frames since the game started.
+	FramesPerSecond = 60
+
+	// Mode names of the timed modes.
+	ModeSprint = "sprint"
+	ModeUltra  = "ultra"
+	ModeDig    = "dig"
+
+	// ModeZen is the mode name of practice games, which accept undo.
+	ModeZen = "zen"
+
+	// ReasonTimeUp is the result reason of games that ended when their time
+	// ran out.
+	ReasonTimeUp = "time_up"
+)
+
+func ElapsedTime(tick uint64) time.Duration {
+	return time.Duration(tick) * time.Second / FramesPerSecond //nolint:gosec // frame counts are far below overflow
+}
+
+// ShowTimer reports whether the sidebar shows a running timer instead of the
+// level.
+func (v *GameView) ShowTimer() bool {
+	return v.Mode == ModeSprint || v.Mode == ModeUltra || v.Mode == ModeDig
+}
+
+// Clock returns the time to show on the timer: the time left in modes with a
+// time limit and the elapsed time otherwise.
+func (v *GameView) Clock() time.Duration {
+	if v.TimeLimit > 0 {
+		return max(v.TimeLimit-v.Elapsed, 0)
+	}
+	return v.Elapsed
+}
+
+// TimerLines formats the timer and line count shown in place of the level.
+func (v *GameView) TimerLines() []string {
+	lines := fmt.Sprintf("Lines: %d", v.Lines)
+	if v.LineGoal > 0 {
+		lines += fmt.Sprintf("/%d", v.LineGoal)
+	}
+	return []string{"Time: " + FormatTimer(v.Clock()), lines}
+}
+
+// FormatTimer formats d as minutes, seconds and hundredths, e.g. 1:05.32.
+func FormatTimer(d time.Duration) string {
+	d = max(d, 0)
+	minutes := d / time.Minute
+	seconds := (d % time.Minute) / time.Second
+	hundredths := (d % time.Second) / (10 * time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%02d", minutes, seconds, hundredths)
+}
+
+// Timer advances the game clock smoothly between state updates, which only
+// arrive when something on the board changes.
+type Timer struct {
+	tick     uint64
+	received time.Time
+	stopped  bool
+	paused   bool
+}
+
+// Update records the tick of a state update received at now.
+func (t *Timer) Update(tick uint64, now time.Time) {
+	t.tick = tick
+	t.received = now
+}
+
+// Stop freezes the timer at the last update, for finished games.
+func (t *Timer) Stop() {
+	t.stopped = true
+}
+
+// SetPaused holds the timer at the last update while the game is paused.
+func (t *Timer) SetPaused(paused bool) {
+	t.paused = paused
+}
+
+// Elapsed returns the game time at now. It runs at most one second ahead of
+// the last update, so a stalled stream does not keep the clock going.
+func (t *Timer) Elapsed(now time.Time) time.Duration {
+	elapsed := ElapsedTime(t.tick)
+	if t.stopped || t.paused || t.received.IsZero() {
+		return elapsed
+	}
+	return elapsed + min(max(now.Sub(t.received), 0), time.Second)
+}
+
+// ResultTitle returns the heading of the end screen for an EVENT_RESULT.
+func ResultTitle(event *pb.GameEvent) string {
+	if event.GetMetadata()["reason"] == ReasonTimeUp {
+		return "TIME UP!"
+	}
+	return "FINISHED!"
+}
+
+// ResultLines formats an EVENT_RESULT for an end screen.
+func ResultLines(event *pb.GameEvent) []string {
+	meta := event.GetMetadata()
+
+	var lines []string
+	if score, ok := meta["score"]; ok && meta["reason"] == ReasonTimeUp {
+		lines = append(lines, "Score: "+score, "Lines: "+meta["lines"])
+	}
+	if ms, err := strconv.ParseInt(meta["time_ms"], 10, 64); err == nil {
+		lines = append(lines, "Time: "+FormatTimer(time.Duration(ms)*time.Millisecond))
+	}
+	if pieces, ok := meta["pieces"]; ok {
+		lines = append(lines, "Pieces: "+pieces)
+	}
+	if pps, ok := meta["pps"]; ok {
+		lines = append(lines, "PPS: "+pps)
+	}
+	return lines
+}
@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/core"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTimer(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                     "0:00.00",
+		65*time.Second + 320*time.Millisecond: "1:05.32",
+		-time.Second:                          "0:00.00",
+	}
+	for d, want := range tests {
+		if got := FormatTimer(d); got != want {
+			t.Errorf("FormatTimer(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestTimer_InterpolatesBetweenUpdates(t *testing.T) {
+	var timer Timer
+	now := time.Unix(100, 0)
+	timer.Update(120, now)
+
+	if got := timer.Elapsed(now.Add(500 * time.Millisecond)); got != 2500*time.Millisecond {
+		t.Errorf("expected 2.5s, got %v", got)
+	}
+	if got := timer.Elapsed(now.Add(time.Minute)); got != 3*time.Second {
+		t.Errorf("expected the timer capped one second past the update, got %v", got)
+	}
+
+	timer.Stop()
+	if got := timer.Elapsed(now.Add(500 * time.Millisecond)); got != 2*time.Second {
+		t.Errorf("expected a stopped timer at 2s, got %v", got)
+	}
+}
+
+func TestResultLines(t *testing.T) {
+	event := &pb.GameEvent{Metadata: map[string]string{"time_ms": "61250", "pieces": "100", "pps": "1.63"}}
+
+	got := strings.Join(ResultLines(event), ", ")
+	if want := "Time: 1:01.25, Pieces: 100, PPS: 1.63"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestGameView_UltraCountsDown(t *testing.T) {
+	view := StateToView(&pb.StateUpdate{
+		Grid:            make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece:    &pb.Piece{},
+		Mode:            ModeUltra,
+		TickId:          FramesPerSecond * 30,
+		TimeRemainingMs: 90_000,
+	})
+	view.Elapsed += 500 * time.Millisecond
+
+	if got := view.TimerLines()[0]; got != "Time: 1:29.50" {
+		t.Errorf("expected the countdown interpolated from the update, got %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+	ErrEmptyKey     = errors.New("empty signing key")
+)
+
+type Identity struct {
+	PlayerID    string
+	DisplayName string
+}
+
+// Authenticator turns the token sent in a JoinRequest into a player
+// identity.
+type Authenticator interface {
+	Authenticate(token string) (Identity, error)
+}
+
+// Anonymous accepts every token and leaves the identity empty, so the match
+// assigns a player id. The engine only uses it when anonymous play is
+// explicitly allowed.
+type Anonymous struct{}
+
+func (Anonymous) Authenticate(string) (Identity, error) {
+	return Identity{}, nil
+}
+
+type claims struct {
+	Subject string `json:"sub"`
+	Name    string `json:"name"`
+	Expiry  int64  `json:"exp"`
+}
+
+// HMAC validates tokens of the form base64(claims).base64(signature) signed
+// with HMAC-SHA256.
+type HMAC struct {
+	key []byte
+	now func() time.Time
+}
+
+func NewHMAC(key []byte) (*HMAC, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	return &HMAC{key: key, now: time.Now}, nil
+}
+
+// LoadKey reads a hex encoded signing key from path.
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(string(bytes.TrimSpace(data)))
+}
+
+func (h *HMAC) Sign(id Identity, expiry time.Time) (string, error) {
+	payload, err := json.Marshal(claims{
+		Subject: id.PlayerID,
+		Name:    id.DisplayName,
+		Expiry:  expiry.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(h.sign(encoded)), nil
+}
+
+func (h *HMAC) Authenticate(token string) (Identity, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Identity{}, ErrInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, h.sign(encoded)) {
+		return Identity{}, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Identity{}, ErrInvalidToken
+	}
+
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+
+	if !h.now().Before(time.Unix(c.Expiry, 0)) {
+		return Identity{}, ErrTokenExpired
+	}
+
+	return Identity{PlayerID: c.Subject, DisplayName: c.Name}, nil
+}
+
+func (h *HMAC) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestHMAC(t *testing.T) *HMAC {
+	t.Helper()
+
+	h, err := NewHMAC([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestHMAC_SignAndAuthenticate(t *testing.T) {
+	h := newTestHMAC(t)
+	want := Identity{PlayerID: "alice", DisplayName: "Alice"}
+
+	token, err := h.Sign(want, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := h.Authenticate(token)
+	if err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestHMAC_RejectsExpiredToken(t *testing.T) {
+	h := newTestHMAC(t)
+
+	token, _ := h.Sign(Identity{PlayerID: "alice"}, time.Now().Add(-time.Second))
+
+	if _, err := h.Authenticate(token); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+}
+
+func TestHMAC_RejectsBadTokens(t *testing.T) {
+	h := newTestHMAC(t)
+	other, _ := NewHMAC([]byte("other"))
+
+	foreign, _ := other.Sign(Identity{PlayerID: "alice"}, time.Now().Add(time.Hour))
+	valid, _ := h.Sign(Identity{PlayerID: "alice"}, time.Now().Add(time.Hour))
+	tampered := "x" + valid[1:]
+
+	for _, token := range []string{"", "token", foreign, tampered} {
+		if _, err := h.Authenticate(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%q: expected ErrInvalidToken, got %v", token, err)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.key")
+	if err := os.WriteFile(path, []byte("0a0b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := LoadKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(key) != "\x0a\x0b" {
+		t.Errorf("unexpected key %x", key)
+	}
+}
@@ -2,7 +2,14 @@ package main
 
 import (
 	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/config"
+	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/services/game-engine/auth"
+	"GoTetrisOnline/services/game-engine/domain"
 	"GoTetrisOnline/services/game-engine/internal/server"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net"
 	"os"
@@ -13,22 +20,65 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
-const (
-	port = ":50051"
-)
-
 func main() {
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "YAML config file")
+	replayDir := flag.String("replays", "", "directory for replays of finished games, empty to disable (engine.replay_dir)")
+	keyPath := flag.String("auth-key", "", "hex encoded HMAC key for join tokens (engine.auth_key)")
+	allowAnonymous := flag.Bool("allow-anonymous", false, "accept any join token when no auth key is set (engine.allow_anonymous)")
+	idleTimeout := flag.Duration("idle-timeout", 0, "drop play streams silent for this long, 0 disables (engine.idle_timeout)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// Flags given on the command line win over the config.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "replays":
+			cfg.Engine.ReplayDir = *replayDir
+		case "auth-key":
+			cfg.Engine.AuthKey = *keyPath
+		case "allow-anonymous":
+			cfg.Engine.AllowAnonymous = *allowAnonymous
+		case "idle-timeout":
+			cfg.Engine.IdleTimeout = *idleTimeout
+		}
+	})
+
+	rules, err := gameRules(cfg.Game)
+	if err != nil {
+		log.Fatalf("invalid game config: %v", err)
+	}
+
+	authenticator, err := newAuthenticator(cfg.Engine)
+	if err != nil {
+		log.Fatalf("failed to set up auth: %v", err)
+	}
+
+	if cfg.Engine.ReplayDir != "" {
+		if err := os.MkdirAll(cfg.Engine.ReplayDir, 0o750); err != nil {
+			log.Fatalf("failed to create replay directory: %v", err)
+		}
+	}
+
 	// todo
 	//nolint:gosec // internal service
-	lis, err := net.Listen("tcp", port)
+	lis, err := net.Listen("tcp", cfg.Engine.Listen)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	log.Printf("Game Engine starting on %s...", port)
+	log.Printf("Game Engine starting on %s...", cfg.Engine.Listen)
 
 	s := grpc.NewServer()
 
-	gameServer := server.NewGrpcServer()
+	gameServer := server.NewGrpcServer(authenticator, server.Options{
+		ReplayDir:   cfg.Engine.ReplayDir,
+		IdleTimeout: cfg.Engine.IdleTimeout,
+		ResumeGrace: cfg.Engine.ResumeGrace,
+		Rules:       rules,
+	})
 	pb.RegisterGameServiceServer(s, gameServer)
 
 	reflection.Register(s)
@@ -47,3 +97,55 @@ func main() {
 	s.GracefulStop()
 	log.Println("Server stopped")
 }
+
+func gameRules(game config.Game) (domain.Rules, error) {
+	randomizer, err := core.ParseRandomizerKind(game.Randomizer)
+	if err != nil {
+		return domain.Rules{}, err
+	}
+	if err := domain.ValidateSprintLines(game.SprintLines); err != nil {
+		return domain.Rules{}, err
+	}
+	if err := domain.ValidateDigLines(game.DigLines); err != nil {
+		return domain.Rules{}, err
+	}
+	if game.UltraTime <= 0 {
+		return domain.Rules{}, fmt.Errorf("ultra time must be positive, got %v", game.UltraTime)
+	}
+	if game.LockDelay <= 0 {
+		return domain.Rules{}, fmt.Errorf("lock delay must be positive, got %v", game.LockDelay)
+	}
+	if game.MaxLockResets <= 0 {
+		return domain.Rules{}, fmt.Errorf("max lock resets must be positive, got %d", game.MaxLockResets)
+	}
+
+	return domain.Rules{
+		StartLevel:    game.StartLevel,
+		LockDelay:     game.LockDelay,
+		MaxLockResets: game.MaxLockResets,
+		LinesPerLevel: game.LinesPerLevel,
+		Randomizer:    randomizer,
+		Mode:          domain.ModeMarathon,
+		SprintLines:   game.SprintLines,
+		UltraTime:     game.UltraTime,
+		DigLines:      game.DigLines,
+	}, nil
+}
+
+// newAuthenticator verifies join tokens with the configured key. Running
+// without one must be asked for explicitly.
+func newAuthenticator(cfg config.Engine) (auth.Authenticator, error) {
+	if cfg.AuthKey == "" {
+		if !cfg.AllowAnonymous {
+			return nil, errors.New("no auth key configured; set engine.auth_key, or engine.allow_anonymous to accept any join token")
+		}
+		log.Println("WARNING: authentication is disabled, accepting any join token")
+		return auth.Anonymous{}, nil
+	}
+
+	key, err := auth.LoadKey(cfg.AuthKey)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewHMAC(key)
+}
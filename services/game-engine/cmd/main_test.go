@@ -0,0 +1,35 @@
+package main
+
+import (
+	"GoTetrisOnline/pkg/config"
+	"testing"
+	"time"
+)
+
+func TestGameRules_Defaults(t *testing.T) {
+	if _, err := gameRules(config.Default().Game); err != nil {
+		t.Fatalf("default game config rejected: %v", err)
+	}
+}
+
+func TestGameRules_RejectsInvalidConfig(t *testing.T) {
+	tests := map[string]func(*config.Game){
+		"randomizer":      func(g *config.Game) { g.Randomizer = "nope" },
+		"sprint lines":    func(g *config.Game) { g.SprintLines = 30 },
+		"dig lines":       func(g *config.Game) { g.DigLines = 11 },
+		"ultra time":      func(g *config.Game) { g.UltraTime = 0 },
+		"lock delay":      func(g *config.Game) { g.LockDelay = 0 },
+		"negative delay":  func(g *config.Game) { g.LockDelay = -time.Second },
+		"max lock resets": func(g *config.Game) { g.MaxLockResets = 0 },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			game := config.Default().Game
+			change(&game)
+			if _, err := gameRules(game); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "GoTetrisOnline/pkg/core"
+
+var (
+	lineAttack      = [...]int32{0, 0, 1, 2, 4}
+	tspinMiniAttack = [...]int32{0, 0, 1}
+	tspinAttack     = [...]int32{0, 2, 4, 6}
+	comboAttack     = [...]int32{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 4, 5}
+)
+
+const (
+	b2bAttack          = 1
+	perfectClearAttack = 10
+)
+
+// attackLines turns a line clear into outgoing garbage using the guideline
+// attack table.
+func attackLines(lines int32, tspin core.TSpin, combo int32, b2b, perfectClear bool) int32 {
+	if lines <= 0 {
+		return 0
+	}
+
+	var attack int32
+	switch {
+	case tspin == core.TSpinMini && int(lines) < len(tspinMiniAttack):
+		attack = tspinMiniAttack[lines]
+	case tspin == core.TSpinFull && int(lines) < len(tspinAttack):
+		attack = tspinAttack[lines]
+	case int(lines) < len(lineAttack):
+		attack = lineAttack[lines]
+	}
+
+	if b2b {
+		attack += b2bAttack
+	}
+
+	if combo > 0 {
+		attack += comboAttack[min(int(combo), len(comboAttack)-1)]
+	}
+
+	if perfectClear {
+		attack += perfectClearAttack
+	}
+
+	return attack
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"testing"
+)
+
+func TestAttackLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   int32
+		tspin   core.TSpin
+		combo   int32
+		b2b     bool
+		perfect bool
+		want    int32
+	}{
+		{name: "no clear", lines: 0, want: 0},
+		{name: "single", lines: 1, want: 0},
+		{name: "double", lines: 2, want: 1},
+		{name: "triple", lines: 3, want: 2},
+		{name: "tetris", lines: 4, want: 4},
+		{name: "b2b tetris", lines: 4, b2b: true, want: 5},
+		{name: "tspin double", lines: 2, tspin: core.TSpinFull, want: 4},
+		{name: "tspin triple", lines: 3, tspin: core.TSpinFull, want: 6},
+		{name: "mini tspin single", lines: 1, tspin: core.TSpinMini, want: 0},
+		{name: "combo 4 single", lines: 1, combo: 4, want: 2},
+		{name: "long combo caps", lines: 1, combo: 40, want: 5},
+		{name: "perfect clear double", lines: 2, perfect: true, want: 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attackLines(tt.lines, tt.tspin, tt.combo, tt.b2b, tt.perfect)
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
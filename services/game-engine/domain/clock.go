@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Clock drives the game loop. Tests and headless simulations can swap in a
+// clock that ticks on demand.
+type Clock interface {
+	Ticker(d time.Duration) (<-chan time.Time, func())
+}
+
+type RealClock struct{}
+
+func (RealClock) Ticker(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"GoTetrisOnline/pkg/core"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -25,44 +26,67 @@ type GameStateDTO struct {
 	Grid         []byte
 	CurrentPiece core.Piece
 	NextPieces   []core.PieceType
+	HeldPiece    core.PieceType
+
+	PendingGarbage int32
+
+	Frame        uint64
+	LastSequence uint64
+
+	Mode          Mode
+	Lines         int32
+	LineGoal      int32
+	TimeRemaining time.Duration
+
+	// Paused is set on the blank snapshots sent while the game is paused.
+	Paused bool
 }
 
+// Game runs a State in real time. It serialises inputs coming from the
+// network, steps the simulation once per frame from its Clock and publishes
+// the resulting events.
 type Game struct {
 	mu sync.RWMutex
+	*State
 
-	Board        *core.Board
-	CurrentPiece core.Piece
-	NextPieces   []core.PieceType
-
-	Score int32
-	Level int32
-	Lines int32
+	UID   string
+	Name  string
+	Clock Clock
 
-	UID    string
-	Status GameStatus
+	events    chan GameEvent
+	quit      chan struct{}
+	closed    bool
+	suspended bool
+	paused    bool
+	replay    *Replay
 
-	events chan GameEvent
-	quit   chan struct{}
+	spectators []*Spectator
 
-	bag *core.Bag
+	onFinish func(*Game)
+	onAttack func(*Game, int32)
 }
 
 func NewGame(uid string) *Game {
+	return NewGameWithRules(uid, DefaultRules(), rand.Uint64())
+}
+
+// NewGameWithRules creates a game whose piece order and garbage holes are
+// fully determined by seed.
+func NewGameWithRules(uid string, rules Rules, seed uint64) *Game {
 	return &Game{
+		State:  NewState(rules, seed),
 		UID:    uid,
-		Status: StatusWaiting,
-		Board:  core.NewBoard(),
+		Clock:  RealClock{},
 		events: make(chan GameEvent, 100),
 		quit:   make(chan struct{}),
-		bag:    core.NewBag(),
+		replay: NewReplay(uid, rules, seed),
 	}
 }
 
 func (g *Game) Start() {
 	g.mu.Lock()
-	g.Status = StatusRunning
-
-	g.CurrentPiece = g.spawnPiece()
+	g.State.Start()
+	g.flush()
 	g.mu.Unlock()
 
 	go g.loop()
@@ -72,166 +96,282 @@ func (g *Game) Stop() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	if g.Status != StatusFinished {
-		g.Status = StatusFinished
-		close(g.quit)
-		close(g.events)
+	g.stop()
+}
+
+func (g *Game) stop() {
+	if g.closed {
+		return
+	}
+
+	g.closed = true
+	g.Status = StatusFinished
+	close(g.quit)
+	close(g.events)
+
+	if g.onFinish != nil {
+		go g.onFinish(g)
+	}
+}
+
+func (g *Game) IsRunning() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.Status == StatusRunning
+}
+
+func (g *Game) IsFinished() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.Status == StatusFinished
+}
+
+// Win ends a running game as the winner of its match.
+func (g *Game) Win() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != StatusRunning {
+		return
 	}
+
+	g.end(GameEvent{Type: "winner", Payload: g.player()})
 }
 
 func (g *Game) Events() <-chan GameEvent {
 	return g.events
 }
 
+// Done is closed once the game has ended, whether it finished or was stopped.
+func (g *Game) Done() <-chan struct{} {
+	return g.quit
+}
+
 func (g *Game) loop() {
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
+	ticks, stop := g.Clock.Ticker(frameDuration)
+	defer stop()
 
 	for {
 		select {
 		case <-g.quit:
 			return
-		case <-ticker.C:
-			g.ApplyGravity()
+		case <-ticks:
+			g.Tick()
 		}
 	}
 }
 
-func (g *Game) ApplyGravity() {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+// flush publishes everything the simulation produced since the last flush.
+// It must be called with g.mu held.
+func (g *Game) flush() {
+	for _, event := range g.Drain() {
+		switch event.Type {
+		case "attack":
+			if g.onAttack != nil {
+				go g.onAttack(g, event.Payload.(int32))
+			}
+		case "game_over", "result":
+			g.end(event)
+		case "state_update":
+			if g.paused {
+				event.Payload = hidden(event.Payload.(GameStateDTO))
+			}
+			g.publish(event)
+		default:
+			g.publish(event)
+		}
+	}
+}
 
-	if g.Status != StatusRunning {
+// end publishes the last event of the game and stops it. The event must not
+// be lost, but nobody may be reading while the player is disconnected, so the
+// oldest queued event makes room for it rather than blocking with g.mu held.
+// It must be called with g.mu held.
+func (g *Game) end(event GameEvent) {
+	if g.closed {
 		return
 	}
 
-	next := g.CurrentPiece
-	next.Position.Y++
-
-	if !g.Board.HasCollision(next) {
-		g.CurrentPiece = next
-		g.broadcast()
-	} else {
-		g.lockAndSpawn()
-		g.broadcast()
-	}
-}
+	for {
+		select {
+		case g.events <- event:
+			g.fanout(event)
+			g.stop()
+			return
+		default:
+		}
 
-func (g *Game) GetSnapshot() GameStateDTO {
-	return GameStateDTO{
-		Score:        g.Score,
-		Level:        g.Level,
-		Grid:         g.Board.ToBytes(),
-		CurrentPiece: g.CurrentPiece,
-		NextPieces:   g.bag.Peek(3),
+		select {
+		case <-g.events:
+		default:
+		}
 	}
 }
 
-func (g *Game) broadcast() {
-	if g.Status == StatusFinished {
+func (g *Game) publish(event GameEvent) {
+	if g.closed {
 		return
 	}
 
 	select {
-	case g.events <- GameEvent{Type: "state_update", Payload: g.GetSnapshot()}:
+	case g.events <- event:
 	default:
 		// skip
 	}
+	g.fanout(event)
 }
 
-func (g *Game) lockAndSpawn() {
-	g.Board.LockPiece(g.CurrentPiece)
-
-	lines := g.Board.ClearLines()
-	g.updateScore(lines)
-
-	g.CurrentPiece = g.spawnPiece()
+func (g *Game) Tick() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	if g.Board.HasCollision(g.CurrentPiece) {
-		g.Status = StatusFinished
-		g.events <- GameEvent{Type: "game_over", Payload: g.Score}
-		close(g.quit)
-		close(g.events)
+	if g.suspended || g.paused {
+		return
 	}
-}
 
-func (g *Game) updateScore(lines int32) {
-	switch lines {
-	case 1:
-		g.Score += 40 * (g.Level + 1)
-	case 2:
-		g.Score += 100 * (g.Level + 1)
-	case 3:
-		g.Score += 300 * (g.Level + 1)
-	case 4:
-		g.Score += 1200 * (g.Level + 1)
-	}
-	g.Lines += lines
+	g.State.Tick()
+	g.flush()
 }
 
-func (g *Game) MoveLeft() {
+// Suspend freezes the game while its player is disconnected.
+func (g *Game) Suspend() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	next := g.CurrentPiece
-	next.Position.X--
-
-	if !g.Board.HasCollision(next) {
-		g.CurrentPiece = next
-		g.broadcast()
-	}
+	g.suspended = true
 }
 
-func (g *Game) MoveRight() {
+// Resume unfreezes a suspended game for a reattached player. Events queued
+// while nobody was listening are dropped in favour of a full snapshot.
+func (g *Game) Resume() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	next := g.CurrentPiece
-	next.Position.X++
+	g.suspended = false
+	if g.closed {
+		return
+	}
 
-	if !g.Board.HasCollision(next) {
-		g.CurrentPiece = next
-		g.broadcast()
+	for len(g.events) > 0 {
+		<-g.events
+	}
+	if g.Status != StatusWaiting {
+		g.publish(GameEvent{Type: "state_update", Payload: g.snapshot()})
 	}
 }
 
-func (g *Game) Rotate(direction int) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+// Replay returns the recording of everything applied to the game so far.
+func (g *Game) Replay() *Replay {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	r := *g.replay
+	r.Entries = append([]ReplayEntry(nil), g.replay.Entries...)
+	r.Frames = g.Frame
+	r.Score = g.Score
+	r.Lines = g.Lines
+	return &r
+}
 
+func (g *Game) record(entry ReplayEntry) {
 	if g.Status != StatusRunning {
 		return
 	}
 
-	rotated, ok := core.TryRotate(g.Board, g.CurrentPiece, direction)
-	if ok {
-		g.CurrentPiece = rotated
-		g.broadcast()
+	entry.Frame = g.Frame
+	g.replay.Entries = append(g.replay.Entries, entry)
+}
+
+func (g *Game) GetSnapshot() GameStateDTO {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.snapshot()
+}
+
+// ReceiveGarbage locks the game and calls State.ReceiveGarbage.
+func (g *Game) ReceiveGarbage(lines int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if lines > 0 {
+		g.record(ReplayEntry{Garbage: lines})
+	}
+	g.State.ReceiveGarbage(lines)
+	g.flush()
+}
+
+func (g *Game) MoveLeft() {
+	g.Apply(0, InputLeft)
+}
+
+func (g *Game) MoveRight() {
+	g.Apply(0, InputRight)
+}
+
+func (g *Game) Rotate(direction int) {
+	if direction == core.RotateCCW {
+		g.Apply(0, InputRotateCCW)
+	} else {
+		g.Apply(0, InputRotateCW)
 	}
 }
 
-func (g *Game) HardDrop() {
+// SoftDrop locks the game and calls State.SoftDrop.
+func (g *Game) SoftDrop() int {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	for {
-		next := g.CurrentPiece
-		next.Position.Y++
+	if g.paused {
+		return 0
+	}
+	g.record(ReplayEntry{Input: InputSoftDrop})
+	cells := g.State.SoftDrop()
+	g.flush()
+	return cells
+}
 
-		if g.Board.HasCollision(next) {
-			g.lockAndSpawn()
-			g.broadcast()
-			return
-		}
+// HardDrop locks the game and calls State.HardDrop.
+func (g *Game) HardDrop() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-		g.CurrentPiece = next
+	if g.paused {
+		return 0
 	}
+	g.record(ReplayEntry{Input: InputHardDrop})
+	cells := g.State.HardDrop()
+	g.flush()
+	return cells
 }
 
-func (g *Game) spawnPiece() core.Piece {
-	return core.Piece{
-		Type:     g.bag.Next(),
-		Position: core.Point{X: 4, Y: 0},
-		Rotation: 0,
+func (g *Game) Hold() {
+	g.Apply(0, InputHold)
+}
+
+// Undo takes back the last placed piece in a zen game.
+func (g *Game) Undo() {
+	g.Apply(0, InputUndo)
+}
+
+// Apply applies a client input identified by its sequence id and records it
+// for the replay. Inputs are ignored while the game is paused.
+func (g *Game) Apply(seq uint64, input Input) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused {
+		return
+	}
+	g.record(ReplayEntry{Sequence: seq, Input: input})
+	g.State.Apply(input)
+	if seq > g.LastSequence {
+		// Acknowledge the input even if it changed nothing, so clients can
+		// drop it from their prediction.
+		g.LastSequence = seq
+		g.dirty = true
 	}
+	g.flush()
 }
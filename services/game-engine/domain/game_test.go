@@ -357,3 +357,458 @@ func TestGame_RotateCCW_MultipleTimes_WrapsAround(t *testing.T) {
 		t.Errorf("After 4 CCW rotations, expected rotation=0, got %d", game.CurrentPiece.Rotation)
 	}
 }
+
+func TestGame_Hold_EmptySlotTakesNextPiece(t *testing.T) {
+	game := NewGame("test-hold-empty")
+	game.Start()
+	defer game.Stop()
+
+	game.mu.RLock()
+	current := game.CurrentPiece.Type
+	next := game.randomizer.Peek(1)[0]
+	game.mu.RUnlock()
+
+	game.Hold()
+
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	if game.HeldPiece != current {
+		t.Errorf("HeldPiece: expected %v, got %v", current, game.HeldPiece)
+	}
+	if game.CurrentPiece.Type != next {
+		t.Errorf("CurrentPiece: expected next piece %v, got %v", next, game.CurrentPiece.Type)
+	}
+}
+
+func TestGame_Hold_SwapsAndResetsOrientation(t *testing.T) {
+	game := NewGame("test-hold-swap")
+	game.Status = StatusRunning
+	game.canHold = true
+	game.HeldPiece = core.PieceI
+	game.CurrentPiece = core.Piece{
+		Type:     core.PieceT,
+		Position: core.Point{X: 2, Y: 10},
+		Rotation: 2,
+	}
+
+	game.Hold()
+
+	if game.HeldPiece != core.PieceT {
+		t.Errorf("HeldPiece: expected T, got %v", game.HeldPiece)
+	}
+
+	expected := core.Piece{Type: core.PieceI, Position: core.Point{X: 4, Y: 0}}
+	if game.CurrentPiece != expected {
+		t.Errorf("CurrentPiece: expected %+v, got %+v", expected, game.CurrentPiece)
+	}
+}
+
+func TestGame_Hold_OnlyOncePerPiece(t *testing.T) {
+	game := NewGame("test-hold-once")
+	game.Status = StatusRunning
+	game.canHold = true
+	game.HeldPiece = core.PieceI
+	game.CurrentPiece = newPiece(core.PieceT)
+
+	game.Hold()
+	game.Hold()
+
+	if game.HeldPiece != core.PieceT || game.CurrentPiece.Type != core.PieceI {
+		t.Errorf("second hold must be ignored: held=%v, current=%v", game.HeldPiece, game.CurrentPiece.Type)
+	}
+
+	game.HardDrop()
+
+	if !game.canHold {
+		t.Error("hold must be available again after the piece locks")
+	}
+}
+
+func TestGame_SoftDrop_MovesAndScores(t *testing.T) {
+	game := NewGame("test-soft-drop")
+	game.Status = StatusRunning
+	game.CurrentPiece = newPiece(core.PieceT)
+
+	cells := game.SoftDrop()
+
+	if cells != 1 {
+		t.Errorf("expected 1 cell, got %d", cells)
+	}
+	if game.CurrentPiece.Position.Y != 1 {
+		t.Errorf("expected Y=1, got %d", game.CurrentPiece.Position.Y)
+	}
+	if game.Score != softDropPoints {
+		t.Errorf("expected Score=%d, got %d", softDropPoints, game.Score)
+	}
+	if game.softDropFrames != framesFor(softDropRelease) {
+		t.Errorf("gravity must speed up while soft dropping, got %d frames", game.softDropFrames)
+	}
+}
+
+func TestGame_SoftDrop_GravityScoresWhileHeld(t *testing.T) {
+	game := NewGame("test-soft-drop-gravity")
+	game.Status = StatusRunning
+	game.CurrentPiece = newPiece(core.PieceT)
+
+	game.SoftDrop()
+	for game.CurrentPiece.Position.Y < 2 {
+		game.Tick()
+	}
+
+	if game.Score != 2*softDropPoints {
+		t.Errorf("expected Score=%d, got %d", 2*softDropPoints, game.Score)
+	}
+}
+
+func TestGame_SoftDrop_BlockedDoesNotLock(t *testing.T) {
+	game := NewGame("test-soft-drop-blocked")
+	game.Status = StatusRunning
+	game.CurrentPiece = core.Piece{Type: core.PieceO, Position: core.Point{X: 4, Y: core.BoardHeight - 2}}
+
+	cells := game.SoftDrop()
+
+	if cells != 0 || game.Score != 0 {
+		t.Errorf("blocked soft drop: cells=%d, score=%d", cells, game.Score)
+	}
+	if game.CurrentPiece.Position.Y != core.BoardHeight-2 {
+		t.Errorf("piece must stay in place, got Y=%d", game.CurrentPiece.Position.Y)
+	}
+}
+
+func TestGame_HardDrop_ScoresPerCell(t *testing.T) {
+	game := NewGame("test-hard-drop")
+	game.Status = StatusRunning
+	game.CurrentPiece = core.Piece{Type: core.PieceO, Position: core.Point{X: 4, Y: 0}}
+
+	cells := game.HardDrop()
+
+	expectedCells := core.BoardHeight - 2
+	if cells != expectedCells {
+		t.Errorf("expected %d cells, got %d", expectedCells, cells)
+	}
+	if game.Score != int32(expectedCells*hardDropPoints) {
+		t.Errorf("expected Score=%d, got %d", expectedCells*hardDropPoints, game.Score)
+	}
+}
+
+func groundedGame(uid string) *Game {
+	game := NewGame(uid)
+	game.Status = StatusRunning
+	game.setCurrentPiece(core.Piece{Type: core.PieceO, Position: core.Point{X: 4, Y: core.BoardHeight - 2}})
+	return game
+}
+
+func TestGame_LockDelay_DoesNotLockOnFirstContact(t *testing.T) {
+	game := groundedGame("test-lock-first-contact")
+
+	game.Tick()
+
+	if game.Board.Get(core.Point{X: 4, Y: core.BoardHeight - 1}) != core.PieceNone {
+		t.Fatal("piece locked without lock delay")
+	}
+	if !game.grounded {
+		t.Error("lock delay must start when the piece touches the stack")
+	}
+}
+
+func TestGame_LockDelay_LocksAfterDeadline(t *testing.T) {
+	game := groundedGame("test-lock-deadline")
+
+	for range framesFor(game.Rules.LockDelay) - 1 {
+		game.Tick()
+	}
+	if game.Board.Get(core.Point{X: 4, Y: core.BoardHeight - 1}) != core.PieceNone {
+		t.Fatal("piece locked before lock delay expired")
+	}
+
+	game.Tick()
+
+	if game.Board.Get(core.Point{X: 4, Y: core.BoardHeight - 1}) != core.PieceO {
+		t.Error("piece must lock once lock delay expires")
+	}
+}
+
+func TestGame_LockDelay_MoveResetsTimer(t *testing.T) {
+	game := groundedGame("test-lock-reset")
+
+	game.Tick()
+	game.lockFrames = 1
+
+	game.MoveLeft()
+
+	if game.lockFrames != framesFor(game.Rules.LockDelay) {
+		t.Error("successful move must restart lock delay")
+	}
+	if game.lockResets != 1 {
+		t.Errorf("expected 1 lock reset, got %d", game.lockResets)
+	}
+}
+
+func TestGame_LockDelay_ResetsAreCapped(t *testing.T) {
+	game := groundedGame("test-lock-cap")
+
+	game.Tick()
+	for range game.Rules.MaxLockResets {
+		game.Rotate(core.RotateCW)
+	}
+
+	game.lockFrames = 1
+	game.Rotate(core.RotateCW)
+
+	if game.lockFrames != 1 {
+		t.Error("lock delay must not restart after max resets")
+	}
+
+	game.Tick()
+
+	if game.Board.Get(core.Point{X: 4, Y: core.BoardHeight - 1}) != core.PieceO {
+		t.Error("piece must lock after max resets")
+	}
+}
+
+func TestGame_LevelUpEveryLinesPerLevel(t *testing.T) {
+	game := NewGame("test-level-up")
+	game.Status = StatusRunning
+
+	if game.Level != 1 {
+		t.Fatalf("expected start level 1, got %d", game.Level)
+	}
+
+	game.updateScore(4, core.TSpinNone)
+	game.updateScore(4, core.TSpinNone)
+	if game.Level != 1 {
+		t.Errorf("8 lines: expected level 1, got %d", game.Level)
+	}
+
+	slow := game.gravity
+	game.updateScore(2, core.TSpinNone)
+	if game.Level != 2 {
+		t.Errorf("10 lines: expected level 2, got %d", game.Level)
+	}
+	if game.gravity <= slow {
+		t.Errorf("gravity must speed up on level up: %v -> %v", slow, game.gravity)
+	}
+}
+
+func TestGame_Tick_20GDropsToStack(t *testing.T) {
+	game := NewGame("test-20g")
+	game.Status = StatusRunning
+	game.setLevel(20)
+	game.setCurrentPiece(newPiece(core.PieceO))
+
+	game.Tick()
+
+	if game.CurrentPiece.Position.Y != core.BoardHeight-2 {
+		t.Errorf("20G: expected piece on the floor, got Y=%d", game.CurrentPiece.Position.Y)
+	}
+}
+
+func TestGame_TSpinDoubleScoring(t *testing.T) {
+	game := NewGame("test-tsd")
+	game.Status = StatusRunning
+
+	for y := core.BoardHeight - 2; y < core.BoardHeight; y++ {
+		for x := 0; x < core.BoardWidth; x++ {
+			game.Board.Set(core.Point{X: x, Y: y}, core.PieceGarbage)
+		}
+	}
+	for _, hole := range []core.Point{{X: 3, Y: 20}, {X: 4, Y: 20}, {X: 5, Y: 20}, {X: 4, Y: 21}} {
+		game.Board.Set(hole, core.PieceNone)
+	}
+	game.Board.Set(core.Point{X: 3, Y: 19}, core.PieceGarbage)
+
+	game.setCurrentPiece(core.Piece{Type: core.PieceT, Position: core.Point{X: 4, Y: 20}, Rotation: 3})
+	game.Rotate(core.RotateCW)
+	game.HardDrop()
+
+	if game.Lines != 2 {
+		t.Fatalf("expected 2 lines, got %d", game.Lines)
+	}
+	if game.Score != tspinPoints[2] {
+		t.Errorf("expected Score=%d, got %d", tspinPoints[2], game.Score)
+	}
+}
+
+func TestGame_MoveAfterRotateCancelsTSpin(t *testing.T) {
+	game := NewGame("test-tspin-cancel")
+	game.Status = StatusRunning
+	game.setCurrentPiece(core.Piece{Type: core.PieceT, Position: core.Point{X: 4, Y: 10}})
+
+	game.Rotate(core.RotateCW)
+	if !game.lastRotate {
+		t.Fatal("rotation must be recorded as the last action")
+	}
+
+	game.MoveLeft()
+	if game.lastRotate {
+		t.Error("move must clear the last rotation")
+	}
+}
+
+func drainEvents(game *Game) []GameEvent {
+	game.mu.Lock()
+	game.flush()
+	game.mu.Unlock()
+
+	var events []GameEvent
+	for {
+		select {
+		case event := <-game.events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func hasEvent(events []GameEvent, eventType string) bool {
+	for _, event := range events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGame_ComboCounter(t *testing.T) {
+	game := NewGame("test-combo")
+	game.Status = StatusRunning
+	game.Board.Set(core.Point{X: 0, Y: core.BoardHeight - 1}, core.PieceGarbage)
+
+	game.updateScore(1, core.TSpinNone)
+	if hasEvent(drainEvents(game), "combo") {
+		t.Error("first clear must not raise a combo event")
+	}
+
+	game.updateScore(1, core.TSpinNone)
+	game.updateScore(1, core.TSpinNone)
+
+	if game.combo != 2 {
+		t.Errorf("expected combo=2, got %d", game.combo)
+	}
+	expected := 3*linePoints[1] + comboPoints*1 + comboPoints*2
+	if game.Score != expected {
+		t.Errorf("expected Score=%d, got %d", expected, game.Score)
+	}
+	if !hasEvent(drainEvents(game), "combo") {
+		t.Error("expected combo event")
+	}
+
+	game.updateScore(0, core.TSpinNone)
+	if game.combo != -1 {
+		t.Errorf("placing without a clear must reset combo, got %d", game.combo)
+	}
+}
+
+func TestGame_BackToBackTetris(t *testing.T) {
+	game := NewGame("test-b2b")
+	game.Status = StatusRunning
+	game.Board.Set(core.Point{X: 0, Y: core.BoardHeight - 1}, core.PieceGarbage)
+
+	game.updateScore(4, core.TSpinNone)
+	game.updateScore(0, core.TSpinNone)
+	drainEvents(game)
+	game.updateScore(4, core.TSpinNone)
+
+	expected := linePoints[4] + linePoints[4]*3/2
+	if game.Score != expected {
+		t.Errorf("expected Score=%d, got %d", expected, game.Score)
+	}
+	if !hasEvent(drainEvents(game), "back_to_back") {
+		t.Error("expected back_to_back event")
+	}
+
+	game.updateScore(1, core.TSpinNone)
+	if game.b2b != -1 {
+		t.Errorf("single must break back-to-back, got %d", game.b2b)
+	}
+}
+
+func TestGame_PerfectClear(t *testing.T) {
+	game := NewGame("test-perfect-clear")
+	game.Status = StatusRunning
+
+	game.updateScore(2, core.TSpinNone)
+
+	expected := linePoints[2] + perfectClearPoints[2]
+	if game.Score != expected {
+		t.Errorf("expected Score=%d, got %d", expected, game.Score)
+	}
+	if !hasEvent(drainEvents(game), "perfect_clear") {
+		t.Error("expected perfect_clear event")
+	}
+}
+
+func TestGame_ReceiveGarbage_AppliedOnLock(t *testing.T) {
+	game := NewGame("test-garbage")
+	game.Status = StatusRunning
+	game.setCurrentPiece(newPiece(core.PieceO))
+
+	game.ReceiveGarbage(3)
+
+	if game.GetSnapshot().PendingGarbage != 3 {
+		t.Fatalf("expected 3 pending lines, got %d", game.GetSnapshot().PendingGarbage)
+	}
+	if !hasEvent(drainEvents(game), "garbage_received") {
+		t.Error("expected garbage_received event")
+	}
+
+	game.HardDrop()
+
+	if len(game.pendingGarbage) != 0 {
+		t.Errorf("garbage must be applied on lock, %d chunks left", len(game.pendingGarbage))
+	}
+
+	garbage := 0
+	for _, cell := range game.Board.Cells {
+		if cell == core.PieceGarbage {
+			garbage++
+		}
+	}
+	if garbage != 3*(core.BoardWidth-1) {
+		t.Errorf("expected %d garbage cells, got %d", 3*(core.BoardWidth-1), garbage)
+	}
+}
+
+func TestGame_ClearCancelsPendingGarbage(t *testing.T) {
+	game := NewGame("test-garbage-cancel")
+	game.Status = StatusRunning
+	game.pendingGarbage = []int32{1, 2}
+
+	left := game.cancelGarbage(2)
+
+	if left != 0 {
+		t.Errorf("expected no attack left, got %d", left)
+	}
+	if game.pendingGarbageLines() != 1 {
+		t.Errorf("expected 1 pending line, got %d", game.pendingGarbageLines())
+	}
+
+	if left := game.cancelGarbage(4); left != 3 {
+		t.Errorf("expected 3 lines left to send, got %d", left)
+	}
+}
+
+func TestGame_SeedDeterminesSpawnOrder(t *testing.T) {
+	const seed = 1234
+	expected := core.NewSeededBag(seed).Peek(4)
+
+	game := NewGameWithRules("test-seed", DefaultRules(), seed)
+	game.Start()
+	defer game.Stop()
+
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	if game.CurrentPiece.Type != expected[0] {
+		t.Errorf("first piece: expected %v, got %v", expected[0], game.CurrentPiece.Type)
+	}
+
+	next := game.GetSnapshot().NextPieces
+	for i, want := range expected[1:] {
+		if next[i] != want {
+			t.Errorf("next[%d]: expected %v, got %v", i, want, next[i])
+		}
+	}
+}
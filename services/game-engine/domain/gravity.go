@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	framesPerSecond = 60
+	frameDuration   = time.Second / framesPerSecond
+	maxGravity      = 20.0
+
+	// gravityUnit is the fixed-point scale used for gravity inside the
+	// simulation, so stepping never depends on floating point rounding.
+	gravityUnit = 1 << 16
+)
+
+// gravityTable is the guideline gravity curve in cells per frame, indexed by
+// level-1. Levels past the end of the table stay at 20G.
+var gravityTable = [...]float64{
+	0.01667, 0.02102, 0.02698, 0.03526, 0.04692,
+	0.06361, 0.08787, 0.12370, 0.17753, 0.25980,
+	0.38781, 0.59065, 0.91811, 1.45696, 2.36118,
+	3.90910, 6.61354, 11.43794, maxGravity,
+}
+
+func GravityForLevel(level int32) float64 {
+	idx := int(level) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(gravityTable) {
+		return maxGravity
+	}
+	return gravityTable[idx]
+}
+
+// gravityPerFrame returns the gravity for level in gravityUnit cells per frame.
+func gravityPerFrame(level int32) int64 {
+	return int64(GravityForLevel(level) * gravityUnit)
+}
+
+// framesFor converts a duration into a whole number of simulation frames.
+func framesFor(d time.Duration) int {
+	return int(d / frameDuration)
+}
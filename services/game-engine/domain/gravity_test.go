@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGravityForLevel_IsMonotonic(t *testing.T) {
+	prev := 0.0
+	for level := int32(1); level <= 30; level++ {
+		g := GravityForLevel(level)
+		if g < prev {
+			t.Errorf("level %d: gravity %v lower than previous %v", level, g, prev)
+		}
+		prev = g
+	}
+}
+
+func TestGravityForLevel_Reaches20G(t *testing.T) {
+	if g := GravityForLevel(20); g != maxGravity {
+		t.Errorf("level 20: expected %vG, got %v", maxGravity, g)
+	}
+	if g := GravityForLevel(100); g != maxGravity {
+		t.Errorf("level 100: expected %vG, got %v", maxGravity, g)
+	}
+}
+
+func TestGravityPerFrame(t *testing.T) {
+	level1 := gravityPerFrame(1)
+	if frames := gravityUnit / level1; frames < 59 || frames > 61 {
+		t.Errorf("level 1: expected ~60 frames per cell, got %d", frames)
+	}
+
+	if g := gravityPerFrame(20); g != maxGravity*gravityUnit {
+		t.Errorf("level 20: expected 20 cells every frame, got %d", g)
+	}
+}
+
+func TestFramesFor(t *testing.T) {
+	if f := framesFor(500 * time.Millisecond); f != 30 {
+		t.Errorf("500ms: expected 30 frames, got %d", f)
+	}
+}
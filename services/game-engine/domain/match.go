@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMatchStarted  = errors.New("match already started")
+	ErrMatchFinished = errors.New("match already finished")
+	ErrAlreadyJoined = errors.New("player already in match")
+)
+
+// Player identifies who controls a game. An empty ID lets the match assign
+// one.
+type Player struct {
+	ID   string
+	Name string
+}
+
+type Match struct {
+	mu sync.Mutex
+
+	ID      string
+	Status  GameStatus
+	Players []*Game
+	Seed    uint64
+	Rules   Rules
+	Winner  Player
+
+	size       int
+	spectators []*Spectator
+	pauseVotes map[*Game]time.Time
+	onFinish   func(*Match)
+	now        func() time.Time
+}
+
+func NewMatch(id string, size int, rules Rules) *Match {
+	return &Match{
+		ID:     id,
+		Status: StatusWaiting,
+		Seed:   rand.Uint64(),
+		Rules:  rules,
+		size:   max(size, 1),
+		now:    time.Now,
+	}
+}
+
+// Join adds a new player to a waiting match and starts every board once the
+// match is full.
+func (m *Match) Join(player Player) (*Game, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Status != StatusWaiting {
+		return nil, ErrMatchStarted
+	}
+
+	if player.ID == "" {
+		player.ID = fmt.Sprintf("player-%d", len(m.Players)+1)
+	}
+	if player.Name == "" {
+		player.Name = player.ID
+	}
+
+	if slices.ContainsFunc(m.Players, func(p *Game) bool { return p.UID == player.ID }) {
+		return nil, ErrAlreadyJoined
+	}
+
+	game := NewGameWithRules(player.ID, m.Rules, m.Seed)
+	game.Name = player.Name
+	game.replay.PlayerName = player.Name
+	game.onFinish = m.handleFinish
+	game.onAttack = m.handleAttack
+	for _, s := range m.spectators {
+		game.addSpectator(s)
+	}
+	m.Players = append(m.Players, game)
+
+	if len(m.Players) >= m.size {
+		m.start()
+	}
+
+	return game, nil
+}
+
+// Leave removes a disconnected player. Leaving a running match counts as
+// topping out.
+func (m *Match) Leave(game *Game) {
+	m.mu.Lock()
+	empty := false
+	if m.Status == StatusWaiting {
+		m.Players = slices.DeleteFunc(m.Players, func(p *Game) bool { return p == game })
+		if len(m.Players) == 0 {
+			m.Status = StatusFinished
+			empty = true
+		}
+	}
+	m.mu.Unlock()
+
+	game.Stop()
+
+	if empty {
+		m.closeSpectators()
+		if m.onFinish != nil {
+			m.onFinish(m)
+		}
+	}
+}
+
+// Result returns the winner once the match has finished with one.
+func (m *Match) Result() (Player, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.Winner, m.Status == StatusFinished && m.Winner.ID != ""
+}
+
+// Spectate attaches a new spectator to every current and future player of
+// the match.
+func (m *Match) Spectate() (*Spectator, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Status == StatusFinished {
+		return nil, ErrMatchFinished
+	}
+
+	s := newSpectator()
+	for _, game := range m.Players {
+		game.addSpectator(s)
+	}
+	m.spectators = append(m.spectators, s)
+	return s, nil
+}
+
+// Unspectate detaches s and closes its event channel.
+func (m *Match) Unspectate(s *Spectator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slices.Contains(m.spectators, s) {
+		return
+	}
+
+	m.spectators = slices.DeleteFunc(m.spectators, func(other *Spectator) bool { return other == s })
+	m.detach(s)
+}
+
+func (m *Match) closeSpectators() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.spectators {
+		m.detach(s)
+	}
+	m.spectators = nil
+}
+
+func (m *Match) detach(s *Spectator) {
+	for _, game := range m.Players {
+		game.removeSpectator(s)
+	}
+	close(s.events)
+}
+
+func (m *Match) start() {
+	m.Status = StatusRunning
+
+	for _, game := range m.Players {
+		game.mu.Lock()
+		game.publish(GameEvent{Type: "match_start", Payload: int32(len(m.Players))}) //nolint:gosec // player count is small
+		game.mu.Unlock()
+	}
+
+	for _, game := range m.Players {
+		game.Start()
+	}
+}
+
+func (m *Match) handleFinish(*Game) {
+	m.mu.Lock()
+	if m.Status != StatusRunning {
+		m.mu.Unlock()
+		return
+	}
+
+	var alive []*Game
+	for _, game := range m.Players {
+		if game.IsRunning() {
+			alive = append(alive, game)
+		}
+	}
+
+	if len(alive) > 1 {
+		m.mu.Unlock()
+		return
+	}
+
+	m.Status = StatusFinished
+	multiplayer := len(m.Players) > 1
+	if len(alive) == 1 && multiplayer {
+		m.Winner = Player{ID: alive[0].UID, Name: alive[0].Name}
+	}
+	m.mu.Unlock()
+
+	if len(alive) == 1 && multiplayer {
+		alive[0].Win()
+	}
+
+	m.closeSpectators()
+	if m.onFinish != nil {
+		m.onFinish(m)
+	}
+}
+
+// handleAttack sends garbage to the next surviving player after the attacker
+// in join order.
+func (m *Match) handleAttack(from *Game, lines int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Status != StatusRunning {
+		return
+	}
+
+	idx := slices.Index(m.Players, from)
+	if idx < 0 {
+		return
+	}
+
+	for i := 1; i < len(m.Players); i++ {
+		target := m.Players[(idx+i)%len(m.Players)]
+		if target.IsRunning() {
+			target.ReceiveGarbage(lines)
+			return
+		}
+	}
+}
+
+type MatchRegistry struct {
+	mu      sync.Mutex
+	matches map[string]*Match
+	size    int
+}
+
+func NewMatchRegistry(playersPerMatch int) *MatchRegistry {
+	return &MatchRegistry{
+		matches: make(map[string]*Match),
+		size:    playersPerMatch,
+	}
+}
+
+// Join adds a player to the match with the given id, creating it with rules
+// if it does not exist yet. Rules of an existing match are kept.
+func (r *MatchRegistry) Join(matchID string, rules Rules, player Player) (*Match, *Game, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match, ok := r.matches[matchID]
+	if !ok {
+		size := r.size
+		if !rules.Mode.Versus() {
+			size = 1
+		}
+		match = NewMatch(matchID, size, rules)
+		match.onFinish = r.remove
+		r.matches[matchID] = match
+	}
+
+	game, err := match.Join(player)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return match, game, nil
+}
+
+func (r *MatchRegistry) Get(matchID string) (*Match, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match, ok := r.matches[matchID]
+	return match, ok
+}
+
+func (r *MatchRegistry) remove(m *Match) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.matches[m.ID] == m {
+		delete(r.matches, m.ID)
+	}
+}
@@ -0,0 +1,385 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMatch_WaitsForPlayers(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	first, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer first.Stop()
+
+	if match.Status != StatusWaiting || first.IsRunning() {
+		t.Fatal("match must wait until enough players join")
+	}
+
+	second, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer second.Stop()
+
+	if match.Status != StatusRunning {
+		t.Errorf("expected match running, got %v", match.Status)
+	}
+
+	for _, game := range []*Game{first, second} {
+		if !game.IsRunning() {
+			t.Errorf("%s: expected game running", game.UID)
+		}
+		if event := <-game.Events(); event.Type != "match_start" {
+			t.Errorf("%s: expected match_start first, got %s", game.UID, event.Type)
+		}
+	}
+
+	if first.UID == second.UID {
+		t.Errorf("players must get distinct ids, both got %s", first.UID)
+	}
+}
+
+func TestMatch_RejectsJoinAfterStart(t *testing.T) {
+	match := NewMatch("room-1", 1, DefaultRules())
+
+	game, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer game.Stop()
+
+	if _, err := match.Join(Player{}); !errors.Is(err, ErrMatchStarted) {
+		t.Errorf("expected ErrMatchStarted, got %v", err)
+	}
+}
+
+func TestMatch_LastSurvivorWins(t *testing.T) {
+	registry := NewMatchRegistry(2)
+
+	match, loser, err := registry.Join("room-1", DefaultRules(), Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	_, winner, err := registry.Join("room-1", DefaultRules(), Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+
+	match.Leave(loser)
+
+	var last GameEvent
+	for event := range winner.Events() {
+		last = event
+	}
+
+	if last.Type != "winner" {
+		t.Fatalf("expected winner event, got %s", last.Type)
+	}
+	if last.Payload != (Player{ID: winner.UID, Name: winner.Name}) {
+		t.Errorf("expected winner %s, got %v", winner.UID, last.Payload)
+	}
+	if match.Winner.ID != winner.UID {
+		t.Errorf("match result must name %s, got %q", winner.UID, match.Winner.ID)
+	}
+
+	waitFor(t, func() bool {
+		_, ok := registry.Get("room-1")
+		return !ok
+	})
+}
+
+func TestMatchRegistry_LeaveWhileWaitingFreesRoom(t *testing.T) {
+	registry := NewMatchRegistry(2)
+
+	match, game, err := registry.Join("room-1", DefaultRules(), Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+
+	match.Leave(game)
+
+	if _, ok := registry.Get("room-1"); ok {
+		t.Error("empty waiting match must be removed")
+	}
+}
+
+func TestMatch_AttackGoesToOpponent(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	attacker, _ := match.Join(Player{})
+	defer attacker.Stop()
+	defender, _ := match.Join(Player{})
+	defer defender.Stop()
+
+	match.handleAttack(attacker, 4)
+
+	defender.mu.RLock()
+	pending := defender.pendingGarbageLines()
+	defender.mu.RUnlock()
+
+	if pending != 4 {
+		t.Errorf("expected 4 pending lines on the opponent, got %d", pending)
+	}
+
+	attacker.mu.RLock()
+	defer attacker.mu.RUnlock()
+	if attacker.pendingGarbageLines() != 0 {
+		t.Error("attacker must not receive its own garbage")
+	}
+}
+
+func TestMatch_PlayersShareSeed(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	first, _ := match.Join(Player{})
+	defer first.Stop()
+	second, _ := match.Join(Player{})
+	defer second.Stop()
+
+	if first.Seed != match.Seed || second.Seed != match.Seed {
+		t.Fatalf("players must use the match seed %d, got %d and %d", match.Seed, first.Seed, second.Seed)
+	}
+
+	first.mu.RLock()
+	second.mu.RLock()
+	defer first.mu.RUnlock()
+	defer second.mu.RUnlock()
+
+	if first.CurrentPiece.Type != second.CurrentPiece.Type {
+		t.Errorf("first piece differs: %v vs %v", first.CurrentPiece.Type, second.CurrentPiece.Type)
+	}
+	if !slices.Equal(first.randomizer.Peek(7), second.randomizer.Peek(7)) {
+		t.Error("players must see the same piece order")
+	}
+}
+
+func TestMatchRegistry_RandomizerChosenPerMatch(t *testing.T) {
+	registry := NewMatchRegistry(1)
+
+	rules := DefaultRules()
+	rules.Randomizer = core.RandomizerTGM
+
+	_, game, err := registry.Join("tgm-room", rules, Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer game.Stop()
+
+	if _, ok := game.randomizer.(*core.TGM); !ok {
+		t.Errorf("expected TGM randomizer, got %T", game.randomizer)
+	}
+}
+
+func TestMatch_SpectatorSeesEveryPlayer(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	first, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer first.Stop()
+
+	spectator, err := match.Spectate()
+	if err != nil {
+		t.Fatalf("spectate failed: %v", err)
+	}
+	defer match.Unspectate(spectator)
+
+	second, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer second.Stop()
+
+	seen := make(map[string]bool)
+	for !seen[first.UID] || !seen[second.UID] {
+		event := <-spectator.Events()
+		if event.Event.Type == "state_update" {
+			seen[event.Player] = true
+		}
+	}
+}
+
+func TestMatch_SpectatorJoinsMidGameWithSnapshot(t *testing.T) {
+	match := NewMatch("room-1", 1, DefaultRules())
+
+	game, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer game.Stop()
+
+	spectator, err := match.Spectate()
+	if err != nil {
+		t.Fatalf("spectate failed: %v", err)
+	}
+	defer match.Unspectate(spectator)
+
+	event := <-spectator.Events()
+	if event.Player != game.UID || event.Event.Type != "state_update" {
+		t.Fatalf("expected a snapshot of %s first, got %s from %s", game.UID, event.Event.Type, event.Player)
+	}
+	if snapshot := event.Event.Payload.(GameStateDTO); snapshot.CurrentPiece.Type == core.PieceNone {
+		t.Error("snapshot must include the current piece")
+	}
+}
+
+func TestMatch_SpectatorClosedWhenMatchEnds(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	loser, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	winner, err := match.Join(Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+
+	spectator, err := match.Spectate()
+	if err != nil {
+		t.Fatalf("spectate failed: %v", err)
+	}
+
+	go func() {
+		for range winner.Events() {
+		}
+	}()
+	match.Leave(loser)
+
+	var last PlayerEvent
+	for event := range spectator.Events() {
+		last = event
+	}
+
+	if last.Event.Type != "winner" || last.Player != winner.UID {
+		t.Errorf("expected winner event for %s last, got %s from %s", winner.UID, last.Event.Type, last.Player)
+	}
+	if _, err := match.Spectate(); !errors.Is(err, ErrMatchFinished) {
+		t.Errorf("expected ErrMatchFinished, got %v", err)
+	}
+}
+
+func TestMatch_JoinUsesPlayerIdentity(t *testing.T) {
+	match := NewMatch("room-1", 2, DefaultRules())
+
+	game, err := match.Join(Player{ID: "alice", Name: "Alice"})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer game.Stop()
+
+	if game.UID != "alice" || game.Name != "Alice" {
+		t.Errorf("expected alice/Alice, got %s/%s", game.UID, game.Name)
+	}
+	if replay := game.Replay(); replay.Player != "alice" || replay.PlayerName != "Alice" {
+		t.Errorf("replay must record the identity, got %s/%s", replay.Player, replay.PlayerName)
+	}
+
+	if _, err := match.Join(Player{ID: "alice"}); !errors.Is(err, ErrAlreadyJoined) {
+		t.Errorf("expected ErrAlreadyJoined, got %v", err)
+	}
+}
+
+func TestMatch_SinglePlayerPauseHidesBoard(t *testing.T) {
+	match := NewMatch("solo", 1, DefaultRules())
+	game, err := match.Join(Player{ID: "p1"})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	defer game.Stop()
+
+	match.RequestPause(game)
+	if !game.IsPaused() {
+		t.Fatal("a single player match must pause at once")
+	}
+
+	snapshot := game.GetSnapshot()
+	if !snapshot.Paused || snapshot.CurrentPiece.Type != core.PieceNone || len(snapshot.NextPieces) != 0 {
+		t.Errorf("paused snapshot must hide the pieces, got %+v", snapshot)
+	}
+
+	game.mu.RLock()
+	frame := game.Frame
+	game.mu.RUnlock()
+	time.Sleep(50 * time.Millisecond)
+	game.HardDrop()
+
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+	if game.Frame != frame || game.Pieces != 0 {
+		t.Errorf("paused game must not advance, got frame %d -> %d, %d pieces", frame, game.Frame, game.Pieces)
+	}
+}
+
+func TestMatch_PauseNeedsEveryPlayer(t *testing.T) {
+	match := NewMatch("room-pause", 2, DefaultRules())
+	first, _ := match.Join(Player{ID: "p1"})
+	second, _ := match.Join(Player{ID: "p2"})
+	defer first.Stop()
+	defer second.Stop()
+	drainEvents(second)
+
+	match.RequestPause(first)
+	if first.IsPaused() || second.IsPaused() {
+		t.Fatal("match must not pause before every player asked")
+	}
+	if !hasEvent(drainEvents(second), "pause_requested") {
+		t.Error("the other player must be asked to pause")
+	}
+
+	match.RequestPause(second)
+	if !first.IsPaused() || !second.IsPaused() {
+		t.Fatal("match must pause once every player asked")
+	}
+
+	match.Unpause(second)
+	if first.IsPaused() || second.IsPaused() {
+		t.Error("any player must be able to resume")
+	}
+	if events := drainEvents(first); !hasEvent(events, "paused") || !hasEvent(events, "resumed") {
+		t.Error("expected paused and resumed events")
+	}
+}
+
+func TestMatch_StalePauseVoteDoesNotCount(t *testing.T) {
+	match := NewMatch("room-stale", 2, DefaultRules())
+	now := time.Unix(100, 0)
+	match.now = func() time.Time { return now }
+
+	first, _ := match.Join(Player{ID: "p1"})
+	second, _ := match.Join(Player{ID: "p2"})
+	defer first.Stop()
+	defer second.Stop()
+
+	match.RequestPause(first)
+	now = now.Add(pauseVoteWindow + time.Second)
+	match.RequestPause(second)
+
+	if first.IsPaused() || second.IsPaused() {
+		t.Fatal("an expired vote must not count as consent")
+	}
+
+	match.RequestPause(first)
+	if !first.IsPaused() || !second.IsPaused() {
+		t.Error("fresh votes from every player must pause the match")
+	}
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Mode selects the goal of a game.
+type Mode string
+
+const (
+	// ModeMarathon is the endless versus game. It is the default.
+	ModeMarathon Mode = "marathon"
+	// ModeSprint ends when the player has cleared Rules.SprintLines lines.
+	ModeSprint Mode = "sprint"
+	// ModeUltra ends when Rules.UltraTime has run out. The player scores as
+	// much as possible before then.
+	ModeUltra Mode = "ultra"
+	// ModeDig starts on a field of garbage rows and ends when the player has
+	// cleared Rules.DigLines of them.
+	ModeDig Mode = "dig"
+	// ModeZen is a practice game without gravity. Topping out clears the
+	// board and InputUndo takes back placed pieces.
+	ModeZen Mode = "zen"
+)
+
+const (
+	DefaultSprintLines = 40
+	DefaultUltraTime   = 2 * time.Minute
+	DefaultDigLines    = 10
+
+	// digRows is how many garbage rows a dig game keeps on the board until
+	// the rest of its goal is smaller.
+	digRows = 10
+)
+
+// EndReason tells why a game reached its mode's goal.
+type EndReason string
+
+const (
+	ReasonLineGoal EndReason = "line_goal"
+	ReasonTimeUp   EndReason = "time_up"
+)
+
+var (
+	modes           = []Mode{ModeMarathon, ModeSprint, ModeUltra, ModeDig, ModeZen}
+	sprintLineGoals = []int32{20, 40, 100}
+	digLineGoals    = []int32{10, 18, 100}
+)
+
+// ParseMode validates a mode name. An empty name selects marathon.
+func ParseMode(name string) (Mode, error) {
+	if name == "" {
+		return ModeMarathon, nil
+	}
+
+	mode := Mode(name)
+	if !slices.Contains(modes, mode) {
+		return "", fmt.Errorf("unknown mode %q", name)
+	}
+	return mode, nil
+}
+
+// ValidateSprintLines checks that lines is one of the supported sprint
+// lengths. Zero selects DefaultSprintLines.
+func ValidateSprintLines(lines int32) error {
+	if lines != 0 && !slices.Contains(sprintLineGoals, lines) {
+		return fmt.Errorf("sprint length must be one of %v lines", sprintLineGoals)
+	}
+	return nil
+}
+
+// ValidateDigLines checks that lines is one of the supported dig goals. Zero
+// selects DefaultDigLines.
+func ValidateDigLines(lines int32) error {
+	if lines != 0 && !slices.Contains(digLineGoals, lines) {
+		return fmt.Errorf("dig goal must be one of %v lines", digLineGoals)
+	}
+	return nil
+}
+
+// ValidateRandomizer checks that games of the mode can use the randomizer.
+// Zen undo rewinds the piece bag, so it needs a bag randomizer.
+func (m Mode) ValidateRandomizer(kind core.RandomizerKind) error {
+	if m == ModeZen && kind != core.Randomizer7Bag && kind != core.Randomizer14Bag {
+		return fmt.Errorf("zen mode needs the %s or %s randomizer", core.Randomizer7Bag, core.Randomizer14Bag)
+	}
+	return nil
+}
+
+// Versus reports whether games of the mode are played against other players.
+// The other modes are single player.
+func (m Mode) Versus() bool {
+	return m == "" || m == ModeMarathon
+}
+
+// GameResult summarises a game that reached its mode's goal.
+type GameResult struct {
+	Mode   Mode
+	Reason EndReason
+	Frames uint64
+	Pieces int32
+	Lines  int32
+	Score  int32
+}
+
+func (r GameResult) Duration() time.Duration {
+	return time.Duration(r.Frames) * time.Second / framesPerSecond //nolint:gosec // frame counts are far below overflow
+}
+
+// PPS returns the pieces placed per second.
+func (r GameResult) PPS() float64 {
+	if r.Frames == 0 {
+		return 0
+	}
+	return float64(r.Pieces) / r.Duration().Seconds()
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"slices"
+	"time"
+)
+
+// pauseVoteWindow is how long a request to pause a multiplayer match waits
+// for the other players to agree.
+const pauseVoteWindow = 10 * time.Second
+
+// Pause freezes a running game and hides its board until Unpause, so the
+// player cannot plan ahead while paused. It reports whether the game was
+// paused by this call.
+func (g *Game) Pause(by Player) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.paused || g.closed || g.Status != StatusRunning {
+		return false
+	}
+
+	g.paused = true
+	g.publish(GameEvent{Type: "paused", Payload: by})
+	g.publish(GameEvent{Type: "state_update", Payload: g.snapshot()})
+	return true
+}
+
+// Unpause continues a paused game and publishes its board again.
+func (g *Game) Unpause(by Player) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused || g.closed {
+		return false
+	}
+
+	g.paused = false
+	g.publish(GameEvent{Type: "resumed", Payload: by})
+	g.publish(GameEvent{Type: "state_update", Payload: g.snapshot()})
+	return true
+}
+
+func (g *Game) IsPaused() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.paused
+}
+
+func (g *Game) player() Player {
+	return Player{ID: g.UID, Name: g.Name}
+}
+
+// notify publishes an event that did not come from the simulation.
+func (g *Game) notify(event GameEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.publish(event)
+}
+
+// snapshot returns the state to publish, with the board hidden while the
+// game is paused. It must be called with g.mu held.
+func (g *Game) snapshot() GameStateDTO {
+	state := g.State.GetSnapshot()
+	if g.paused {
+		state = hidden(state)
+	}
+	return state
+}
+
+// hidden blanks everything on state that would help planning.
+func hidden(state GameStateDTO) GameStateDTO {
+	state.Paused = true
+	state.Grid = make([]byte, len(state.Grid))
+	state.CurrentPiece = core.Piece{}
+	state.NextPieces = nil
+	state.HeldPiece = core.PieceNone
+	return state
+}
+
+// RequestPause asks to pause the match on behalf of game's player. A single
+// player match pauses at once. With more players every running player has
+// to ask within pauseVoteWindow; until then the others are told who is
+// waiting for them.
+func (m *Match) RequestPause(game *Game) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Status != StatusRunning || !slices.Contains(m.Players, game) || game.IsPaused() {
+		return
+	}
+
+	now := m.now()
+	for p, at := range m.pauseVotes {
+		if now.Sub(at) > pauseVoteWindow {
+			delete(m.pauseVotes, p)
+		}
+	}
+
+	if m.pauseVotes == nil {
+		m.pauseVotes = make(map[*Game]time.Time)
+	}
+	_, voted := m.pauseVotes[game]
+	m.pauseVotes[game] = now
+
+	var running []*Game
+	for _, p := range m.Players {
+		if p.IsRunning() {
+			running = append(running, p)
+		}
+	}
+
+	if slices.ContainsFunc(running, func(p *Game) bool {
+		_, ok := m.pauseVotes[p]
+		return !ok
+	}) {
+		if !voted {
+			for _, p := range running {
+				p.notify(GameEvent{Type: "pause_requested", Payload: game.player()})
+			}
+		}
+		return
+	}
+
+	m.pauseVotes = nil
+	for _, p := range running {
+		p.Pause(game.player())
+	}
+}
+
+// Unpause continues every paused board of the match. Any player can resume.
+func (m *Match) Unpause(game *Game) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slices.Contains(m.Players, game) {
+		return
+	}
+
+	m.pauseVotes = nil
+	for _, p := range m.Players {
+		p.Unpause(game.player())
+	}
+}
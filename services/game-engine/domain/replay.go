@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const ReplayVersion = 1
+
+var ErrReplayVersion = errors.New("unsupported replay version")
+
+// Replay holds everything needed to re-simulate a game: the seed, the rules
+// and every input and incoming garbage in the order the engine applied them.
+type Replay struct {
+	Version    int    `json:"version"`
+	Match      string `json:"match,omitempty"`
+	Player     string `json:"player"`
+	PlayerName string `json:"player_name,omitempty"`
+	Seed       uint64 `json:"seed"`
+	Rules      Rules  `json:"rules"`
+
+	Entries []ReplayEntry `json:"entries"`
+
+	Frames uint64 `json:"frames"`
+	Score  int32  `json:"score"`
+	Lines  int32  `json:"lines"`
+}
+
+// ReplayEntry is a single input or garbage delivery applied after Frame
+// frames had been simulated.
+type ReplayEntry struct {
+	Frame    uint64 `json:"frame"`
+	Sequence uint64 `json:"seq,omitempty"`
+	Input    Input  `json:"input,omitempty"`
+	Garbage  int32  `json:"garbage,omitempty"`
+}
+
+func NewReplay(player string, rules Rules, seed uint64) *Replay {
+	return &Replay{
+		Version: ReplayVersion,
+		Player:  player,
+		Seed:    seed,
+		Rules:   rules,
+	}
+}
+
+func (r *Replay) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return enc.Encode(r)
+}
+
+func (r *Replay) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func ReadReplay(rd io.Reader) (*Replay, error) {
+	var r Replay
+	if err := json.NewDecoder(rd).Decode(&r); err != nil {
+		return nil, err
+	}
+
+	if r.Version != ReplayVersion {
+		return nil, fmt.Errorf("%w: %d", ErrReplayVersion, r.Version)
+	}
+	return &r, nil
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadReplay(f)
+}
+
+// Playback re-simulates a replay one frame at a time.
+type Playback struct {
+	State *State
+
+	replay *Replay
+	next   int
+}
+
+func NewPlayback(r *Replay) *Playback {
+	s := NewState(r.Rules, r.Seed)
+	s.Start()
+
+	p := &Playback{State: s, replay: r}
+	p.applyPending()
+	s.Drain()
+	return p
+}
+
+// Step advances the simulation by one frame. It returns false once the
+// replay has ended.
+func (p *Playback) Step() bool {
+	if p.Done() {
+		return false
+	}
+
+	p.State.Tick()
+	p.applyPending()
+	p.State.Drain()
+	return true
+}
+
+func (p *Playback) Done() bool {
+	return p.State.Status != StatusRunning || p.State.Frame >= p.replay.Frames
+}
+
+func (p *Playback) applyPending() {
+	for p.next < len(p.replay.Entries) {
+		entry := p.replay.Entries[p.next]
+		if entry.Frame != p.State.Frame {
+			return
+		}
+
+		if entry.Garbage > 0 {
+			p.State.ReceiveGarbage(entry.Garbage)
+		} else {
+			p.State.Apply(entry.Input)
+		}
+		p.next++
+	}
+}
+
+// Simulate plays the replay to the end without rendering and returns the
+// final state.
+func Simulate(r *Replay) *State {
+	p := NewPlayback(r)
+	for p.Step() {
+	}
+	return p.State
+}
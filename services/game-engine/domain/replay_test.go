@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func recordGame(t *testing.T, seed uint64) *Game {
+	t.Helper()
+
+	game := NewGameWithRules("test-replay", DefaultRules(), seed)
+	game.Clock = &manualClock{ticks: make(chan time.Time)}
+	game.Start()
+	t.Cleanup(game.Stop)
+
+	go func() {
+		for range game.Events() {
+		}
+	}()
+
+	inputs := rand.New(rand.NewPCG(seed, 7))
+	for seq := uint64(1); seq <= 5000 && game.IsRunning(); seq++ {
+		game.Apply(seq, simInputs[inputs.IntN(len(simInputs))])
+		if inputs.IntN(150) == 0 {
+			game.ReceiveGarbage(int32(1 + inputs.IntN(3))) //nolint:gosec // small value
+		}
+		game.Tick()
+	}
+	return game
+}
+
+func TestReplay_SimulationMatchesRecordedGame(t *testing.T) {
+	for seed := range uint64(20) {
+		game := recordGame(t, seed)
+		replay := game.Replay()
+
+		state := Simulate(replay)
+
+		if state.Score != replay.Score || state.Lines != replay.Lines || state.Frame != replay.Frames {
+			t.Fatalf("seed %d: replay diverged: score %d/%d, lines %d/%d, frames %d/%d", seed,
+				state.Score, replay.Score, state.Lines, replay.Lines, state.Frame, replay.Frames)
+		}
+		if !slices.Equal(state.Board.ToBytes(), game.GetSnapshot().Grid) {
+			t.Fatalf("seed %d: final boards differ", seed)
+		}
+	}
+}
+
+func TestReplay_RecordsSequenceAndFrame(t *testing.T) {
+	game := NewGameWithRules("test-replay-seq", DefaultRules(), 1)
+	game.Clock = &manualClock{ticks: make(chan time.Time)}
+	game.Start()
+	defer game.Stop()
+
+	game.Tick()
+	game.Tick()
+	game.Apply(9, InputLeft)
+
+	entries := game.Replay().Entries
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0] != (ReplayEntry{Frame: 2, Sequence: 9, Input: InputLeft}) {
+		t.Errorf("unexpected entry %+v", entries[0])
+	}
+}
+
+func TestReplay_WriteReadRoundTrip(t *testing.T) {
+	replay := recordGame(t, 3).Replay()
+
+	var buf bytes.Buffer
+	if err := replay.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.Seed != replay.Seed || read.Rules != replay.Rules || !slices.Equal(read.Entries, replay.Entries) {
+		t.Error("replay changed after a write/read round trip")
+	}
+	if Simulate(read).Score != replay.Score {
+		t.Error("decoded replay must reproduce the recorded score")
+	}
+}
+
+func TestReadReplay_RejectsUnknownVersion(t *testing.T) {
+	_, err := ReadReplay(strings.NewReader(`{"version": 99}`))
+	if !errors.Is(err, ErrReplayVersion) {
+		t.Errorf("expected ErrReplayVersion, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"time"
+)
+
+const (
+	defaultLockDelay     = 500 * time.Millisecond
+	defaultMaxLockResets = 15
+	defaultLinesPerLevel = 10
+)
+
+type Rules struct {
+	StartLevel    int32               `json:"start_level,omitempty"`
+	LockDelay     time.Duration       `json:"lock_delay"`
+	MaxLockResets int                 `json:"max_lock_resets"`
+	LinesPerLevel int32               `json:"lines_per_level"`
+	Randomizer    core.RandomizerKind `json:"randomizer"`
+
+	Mode        Mode          `json:"mode,omitempty"`
+	SprintLines int32         `json:"sprint_lines,omitempty"`
+	UltraTime   time.Duration `json:"ultra_time,omitempty"`
+	DigLines    int32         `json:"dig_lines,omitempty"`
+	// CleanCheese keeps the hole of the dig garbage in one column instead of
+	// moving it on every row.
+	CleanCheese bool `json:"clean_cheese,omitempty"`
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		StartLevel:    1,
+		LockDelay:     defaultLockDelay,
+		MaxLockResets: defaultMaxLockResets,
+		LinesPerLevel: defaultLinesPerLevel,
+		Randomizer:    core.Randomizer7Bag,
+		Mode:          ModeMarathon,
+	}
+}
+
+// startLevel is the level a game starts at. Rules recorded before the start
+// level was configurable leave it zero.
+func (r Rules) startLevel() int32 {
+	return max(r.StartLevel, 1)
+}
+
+// lineGoal returns the lines that end the game, or zero for modes without a
+// line goal.
+func (r Rules) lineGoal() int32 {
+	switch {
+	case r.Mode == ModeSprint && r.SprintLines == 0:
+		return DefaultSprintLines
+	case r.Mode == ModeSprint:
+		return r.SprintLines
+	case r.Mode == ModeDig && r.DigLines == 0:
+		return DefaultDigLines
+	case r.Mode == ModeDig:
+		return r.DigLines
+	}
+	return 0
+}
+
+// timeLimit returns the frames after which the game ends, or zero for modes
+// without a time limit.
+func (r Rules) timeLimit() uint64 {
+	if r.Mode != ModeUltra {
+		return 0
+	}
+	if r.UltraTime <= 0 {
+		return uint64(framesFor(DefaultUltraTime))
+	}
+	return uint64(framesFor(r.UltraTime)) //nolint:gosec // positive duration
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "slices"
+
+const spectatorBuffer = 256
+
+// PlayerEvent is a game event tagged with the player it belongs to.
+type PlayerEvent struct {
+	Player string
+	Name   string
+	Event  GameEvent
+}
+
+// Spectator receives the events of every player in a match. Slow spectators
+// miss events instead of holding up the game.
+type Spectator struct {
+	events chan PlayerEvent
+}
+
+func newSpectator() *Spectator {
+	return &Spectator{
+		events: make(chan PlayerEvent, spectatorBuffer),
+	}
+}
+
+// Events is closed when the spectator is detached or the match ends.
+func (s *Spectator) Events() <-chan PlayerEvent {
+	return s.events
+}
+
+func (s *Spectator) send(g *Game, event GameEvent) {
+	select {
+	case s.events <- PlayerEvent{Player: g.UID, Name: g.Name, Event: event}:
+	default:
+		// skip
+	}
+}
+
+// addSpectator attaches s to the game, starting with a full snapshot so the
+// spectator can join mid-game.
+func (g *Game) addSpectator(s *Spectator) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != StatusWaiting {
+		s.send(g, GameEvent{Type: "state_update", Payload: g.snapshot()})
+	}
+	g.spectators = append(g.spectators, s)
+}
+
+func (g *Game) removeSpectator(s *Spectator) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.spectators = slices.DeleteFunc(g.spectators, func(other *Spectator) bool { return other == s })
+}
+
+// fanout must be called with g.mu held.
+func (g *Game) fanout(event GameEvent) {
+	for _, s := range g.spectators {
+		s.send(g, event)
+	}
+}
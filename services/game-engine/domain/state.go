@@ -0,0 +1,635 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	softDropFactor  = 20
+	softDropRelease = 250 * time.Millisecond
+
+	softDropPoints = 1
+	hardDropPoints = 2
+)
+
+// Drop reports how far a soft or hard drop input moved the piece and what it
+// scored.
+type Drop struct {
+	Hard   bool
+	Cells  int32
+	Points int32
+}
+
+// garbageStream separates the garbage hole RNG from the randomizer so both can be
+// derived from the same seed.
+const garbageStream = 1
+
+type Input int
+
+const (
+	InputNone Input = iota
+	InputLeft
+	InputRight
+	InputRotateCW
+	InputRotateCCW
+	InputSoftDrop
+	InputHardDrop
+	InputHold
+	InputUndo
+)
+
+// State is the simulation of a single board. It only changes through Start,
+// Apply, ReceiveGarbage and Tick and never reads the wall clock, so the same
+// rules, seed and inputs always produce the same game frame by frame.
+type State struct {
+	Board        *core.Board
+	CurrentPiece core.Piece
+	HeldPiece    core.PieceType
+
+	Score  int32
+	Level  int32
+	Lines  int32
+	Pieces int32
+
+	Status GameStatus
+	Rules  Rules
+	Seed   uint64
+	Frame  uint64
+
+	// LastSequence is the sequence id of the last client input applied.
+	LastSequence uint64
+
+	gravity        int64
+	gravityAcc     int64
+	softDropFrames int
+
+	grounded   bool
+	lockFrames int
+	lockResets int
+	lowestY    int
+
+	lastRotate bool
+	lastKick   int
+
+	combo int32
+	b2b   int32
+
+	randomizer core.Randomizer
+	rng        *rand.Rand
+	canHold    bool
+
+	pendingGarbage []int32
+	cheeseAdded    int32
+
+	spawned *undoPoint
+	undo    []undoPoint
+
+	events []GameEvent
+	dirty  bool
+}
+
+func NewState(rules Rules, seed uint64) *State {
+	s := &State{
+		Board:  core.NewBoard(),
+		Status: StatusWaiting,
+		Rules:  rules,
+		Seed:   seed,
+		combo:  -1,
+		b2b:    -1,
+
+		randomizer: core.NewRandomizer(rules.Randomizer, seed),
+		rng:        rand.New(rand.NewPCG(seed, garbageStream)),
+	}
+	s.setLevel(rules.startLevel())
+	return s
+}
+
+func (s *State) Start() {
+	if s.Status != StatusWaiting {
+		return
+	}
+
+	s.Status = StatusRunning
+	if s.Rules.Mode == ModeDig {
+		s.fillCheese()
+	}
+	s.setCurrentPiece(s.spawnPiece())
+	s.canHold = true
+	s.saveUndo()
+	s.dirty = true
+}
+
+// Step applies the inputs for one frame, advances the simulation by that
+// frame and returns the events it produced.
+func (s *State) Step(inputs []Input) []GameEvent {
+	for _, input := range inputs {
+		s.Apply(input)
+	}
+	s.Tick()
+	return s.Drain()
+}
+
+// Drain returns and clears the events produced since the last call. A
+// state_update with a fresh snapshot comes first if anything visible changed.
+func (s *State) Drain() []GameEvent {
+	events := s.events
+	if s.dirty {
+		events = append([]GameEvent{{Type: "state_update", Payload: s.GetSnapshot()}}, events...)
+	}
+
+	s.events = nil
+	s.dirty = false
+	return events
+}
+
+func (s *State) Apply(input Input) {
+	switch input {
+	case InputLeft:
+		s.MoveLeft()
+	case InputRight:
+		s.MoveRight()
+	case InputRotateCW:
+		s.Rotate(core.RotateCW)
+	case InputRotateCCW:
+		s.Rotate(core.RotateCCW)
+	case InputSoftDrop:
+		s.SoftDrop()
+	case InputHardDrop:
+		s.HardDrop()
+	case InputHold:
+		s.Hold()
+	case InputUndo:
+		s.Undo()
+	}
+}
+
+// Tick advances the simulation by one frame: gravity, soft drop and lock
+// delay.
+func (s *State) Tick() {
+	if s.Status != StatusRunning {
+		return
+	}
+
+	s.Frame++
+	if limit := s.Rules.timeLimit(); limit > 0 && s.Frame >= limit {
+		s.complete(ReasonTimeUp)
+		return
+	}
+
+	gravity := s.gravity
+	softDropping := s.softDropFrames > 0
+	if softDropping {
+		gravity = min(gravity*softDropFactor, maxGravity*gravityUnit)
+		s.softDropFrames--
+	} else if s.Rules.Mode == ModeZen {
+		gravity = 0
+	}
+
+	s.gravityAcc += gravity
+	cells := s.gravityAcc / gravityUnit
+	s.gravityAcc %= gravityUnit
+
+	fell := 0
+	for range cells {
+		next := s.CurrentPiece
+		next.Position.Y++
+
+		if s.Board.HasCollision(next) {
+			s.gravityAcc = 0
+			break
+		}
+
+		s.fall(next)
+		fell++
+	}
+
+	if fell > 0 {
+		if softDropping {
+			s.Score += int32(fell * softDropPoints) //nolint:gosec // board is 22 cells high
+		}
+		s.dirty = true
+	}
+
+	if !s.onGround() {
+		s.grounded = false
+		return
+	}
+
+	if !s.grounded {
+		s.grounded = true
+		s.lockFrames = framesFor(s.Rules.LockDelay)
+	}
+
+	s.lockFrames--
+	if s.lockFrames <= 0 {
+		s.lockAndSpawn()
+		s.dirty = true
+	}
+}
+
+func (s *State) onGround() bool {
+	next := s.CurrentPiece
+	next.Position.Y++
+	return s.Board.HasCollision(next)
+}
+
+func (s *State) fall(next core.Piece) {
+	s.CurrentPiece = next
+	s.grounded = false
+	s.lastRotate = false
+
+	if next.Position.Y > s.lowestY {
+		s.lowestY = next.Position.Y
+		s.lockResets = 0
+	}
+}
+
+func (s *State) resetLockDelay() {
+	if !s.grounded || s.lockResets >= s.Rules.MaxLockResets {
+		return
+	}
+
+	s.lockResets++
+	s.lockFrames = framesFor(s.Rules.LockDelay)
+}
+
+func (s *State) setCurrentPiece(p core.Piece) {
+	s.CurrentPiece = p
+	s.grounded = false
+	s.lockResets = 0
+	s.lowestY = p.Position.Y
+	s.lastRotate = false
+	s.gravityAcc = 0
+}
+
+func (s *State) GetSnapshot() GameStateDTO {
+	return GameStateDTO{
+		Score:        s.Score,
+		Level:        s.Level,
+		Grid:         s.Board.ToBytes(),
+		CurrentPiece: s.CurrentPiece,
+		NextPieces:   s.randomizer.Peek(3),
+		HeldPiece:    s.HeldPiece,
+
+		PendingGarbage: s.pendingGarbageLines(),
+
+		Frame:        s.Frame,
+		LastSequence: s.LastSequence,
+
+		Mode:          s.Rules.Mode,
+		Lines:         s.goalLines(),
+		LineGoal:      s.Rules.lineGoal(),
+		TimeRemaining: s.timeRemaining(),
+	}
+}
+
+// timeRemaining returns the time left in a timed mode, or zero otherwise.
+func (s *State) timeRemaining() time.Duration {
+	limit := s.Rules.timeLimit()
+	if limit <= s.Frame {
+		return 0
+	}
+	return time.Duration(limit-s.Frame) * time.Second / framesPerSecond //nolint:gosec // frame counts are far below overflow
+}
+
+func (s *State) emit(event GameEvent) {
+	s.events = append(s.events, event)
+}
+
+func (s *State) lockAndSpawn() {
+	tspin := core.DetectTSpin(s.Board, s.CurrentPiece, s.lastRotate, s.lastKick)
+	s.pushUndo()
+	s.Board.LockPiece(s.CurrentPiece)
+
+	lines := s.Board.ClearLines()
+	attack := s.updateScore(lines, tspin)
+	s.Pieces++
+
+	if goal := s.Rules.lineGoal(); goal > 0 && s.goalLines() >= goal {
+		s.complete(ReasonLineGoal)
+		return
+	}
+
+	toppedOut := false
+	if lines > 0 {
+		attack = s.cancelGarbage(attack)
+		if attack > 0 {
+			s.emit(GameEvent{Type: "attack", Payload: attack})
+		}
+		if s.Rules.Mode == ModeDig {
+			toppedOut = !s.fillCheese()
+		}
+	} else {
+		toppedOut = !s.applyGarbage()
+	}
+
+	s.setCurrentPiece(s.spawnPiece())
+	s.canHold = true
+	s.softDropFrames = 0
+	s.saveUndo()
+
+	if toppedOut || s.Board.HasCollision(s.CurrentPiece) {
+		s.topOut()
+	}
+}
+
+// ReceiveGarbage queues incoming garbage lines. They are added to the board
+// the next time a piece locks without clearing lines.
+func (s *State) ReceiveGarbage(lines int32) {
+	if s.Status != StatusRunning || lines <= 0 {
+		return
+	}
+
+	s.pendingGarbage = append(s.pendingGarbage, lines)
+	s.emit(GameEvent{Type: "garbage_received", Payload: lines})
+	s.dirty = true
+}
+
+func (s *State) pendingGarbageLines() int32 {
+	var total int32
+	for _, lines := range s.pendingGarbage {
+		total += lines
+	}
+	return total
+}
+
+func (s *State) cancelGarbage(attack int32) int32 {
+	for attack > 0 && len(s.pendingGarbage) > 0 {
+		cancelled := min(attack, s.pendingGarbage[0])
+		attack -= cancelled
+		s.pendingGarbage[0] -= cancelled
+
+		if s.pendingGarbage[0] == 0 {
+			s.pendingGarbage = s.pendingGarbage[1:]
+		}
+	}
+	return attack
+}
+
+func (s *State) applyGarbage() bool {
+	ok := true
+	for _, lines := range s.pendingGarbage {
+		if !s.Board.AddGarbage(int(lines), s.rng.IntN(core.BoardWidth)) {
+			ok = false
+		}
+	}
+	s.pendingGarbage = nil
+	return ok
+}
+
+// fillCheese tops the dig garbage up to digRows rows, without adding more
+// rows than the goal in total. It reports false if the stack was pushed off
+// the board.
+func (s *State) fillCheese() bool {
+	rows := min(digRows-int32(s.Board.GarbageRows()), s.Rules.lineGoal()-s.cheeseAdded) //nolint:gosec // row counts are small
+	if rows <= 0 {
+		return true
+	}
+
+	s.cheeseAdded += rows
+	return s.Board.AddCheese(int(rows), !s.Rules.CleanCheese, s.rng)
+}
+
+// goalLines returns the lines that count towards the mode's line goal. Dig
+// games only count garbage rows.
+func (s *State) goalLines() int32 {
+	if s.Rules.Mode == ModeDig {
+		return s.cheeseAdded - int32(s.Board.GarbageRows()) //nolint:gosec // row counts are small
+	}
+	return s.Lines
+}
+
+// topOut ends the game, except in zen mode where it clears the board and
+// play goes on.
+func (s *State) topOut() {
+	if s.Rules.Mode != ModeZen {
+		s.finish()
+		return
+	}
+
+	s.Board.Clear()
+	s.pendingGarbage = nil
+	// The undo point of the current piece still holds the old stack.
+	s.saveUndo()
+	s.dirty = true
+}
+
+func (s *State) finish() {
+	s.Status = StatusFinished
+	s.emit(GameEvent{Type: "game_over", Payload: s.Score})
+}
+
+// complete ends a game that reached the goal of its mode.
+func (s *State) complete(reason EndReason) {
+	s.Status = StatusFinished
+	s.dirty = true
+	s.emit(GameEvent{Type: "result", Payload: GameResult{
+		Mode:   s.Rules.Mode,
+		Reason: reason,
+		Frames: s.Frame,
+		Pieces: s.Pieces,
+		Lines:  s.goalLines(),
+		Score:  s.Score,
+	}})
+}
+
+var (
+	linePoints         = [...]int32{0, 100, 300, 500, 800}
+	tspinMiniPoints    = [...]int32{100, 200, 400}
+	tspinPoints        = [...]int32{400, 800, 1200, 1600}
+	perfectClearPoints = [...]int32{0, 800, 1200, 1800, 2000}
+)
+
+const (
+	comboPoints            = 50
+	b2bPerfectTetrisPoints = 3200
+)
+
+func (s *State) updateScore(lines int32, tspin core.TSpin) int32 {
+	var points int32
+	switch {
+	case tspin == core.TSpinMini && int(lines) < len(tspinMiniPoints):
+		points = tspinMiniPoints[lines]
+	case tspin == core.TSpinFull && int(lines) < len(tspinPoints):
+		points = tspinPoints[lines]
+	case int(lines) < len(linePoints):
+		points = linePoints[lines]
+	}
+
+	if lines == 0 {
+		s.combo = -1
+		s.Score += points * s.Level
+		return 0
+	}
+
+	difficult := lines == 4 || tspin != core.TSpinNone
+	wasB2B := s.b2b >= 0
+	switch {
+	case difficult:
+		s.b2b++
+		if s.b2b > 0 {
+			points += points / 2
+			s.emit(GameEvent{Type: "back_to_back", Payload: s.b2b})
+		}
+	default:
+		s.b2b = -1
+	}
+
+	s.combo++
+	if s.combo > 0 {
+		points += comboPoints * s.combo
+		s.emit(GameEvent{Type: "combo", Payload: s.combo})
+	}
+
+	perfectClear := s.Board.IsEmpty()
+	if perfectClear {
+		if lines == 4 && wasB2B {
+			points += b2bPerfectTetrisPoints
+		} else if int(lines) < len(perfectClearPoints) {
+			points += perfectClearPoints[lines]
+		}
+		s.emit(GameEvent{Type: "perfect_clear", Payload: lines})
+	}
+
+	s.Score += points * s.Level
+	s.Lines += lines
+
+	if s.Rules.LinesPerLevel > 0 {
+		if level := s.Rules.startLevel() + s.Lines/s.Rules.LinesPerLevel; level > s.Level {
+			s.setLevel(level)
+		}
+	}
+
+	return attackLines(lines, tspin, s.combo, s.b2b > 0, perfectClear)
+}
+
+func (s *State) setLevel(level int32) {
+	s.Level = level
+	s.gravity = gravityPerFrame(level)
+}
+
+func (s *State) MoveLeft() {
+	s.shift(-1)
+}
+
+func (s *State) MoveRight() {
+	s.shift(1)
+}
+
+func (s *State) shift(dx int) {
+	if s.Status != StatusRunning {
+		return
+	}
+
+	next := s.CurrentPiece
+	next.Position.X += dx
+
+	if !s.Board.HasCollision(next) {
+		s.CurrentPiece = next
+		s.lastRotate = false
+		s.resetLockDelay()
+		s.dirty = true
+	}
+}
+
+func (s *State) Rotate(direction int) {
+	if s.Status != StatusRunning {
+		return
+	}
+
+	rotated, kick, ok := core.TryRotateKick(s.Board, s.CurrentPiece, direction)
+	if ok {
+		s.CurrentPiece = rotated
+		s.lastRotate = true
+		s.lastKick = kick
+		s.resetLockDelay()
+		s.dirty = true
+	}
+}
+
+// SoftDrop moves the piece one cell down and keeps gravity sped up until
+// no soft drop input arrives for softDropRelease. It returns the number of
+// cells the piece moved, which is also reported in a drop event.
+func (s *State) SoftDrop() int {
+	if s.Status != StatusRunning {
+		return 0
+	}
+
+	s.softDropFrames = framesFor(softDropRelease)
+
+	next := s.CurrentPiece
+	next.Position.Y++
+
+	if s.Board.HasCollision(next) {
+		return 0
+	}
+
+	s.fall(next)
+	s.Score += softDropPoints
+	s.dirty = true
+	s.emit(GameEvent{Type: "drop", Payload: Drop{Cells: 1, Points: softDropPoints}})
+	return 1
+}
+
+// HardDrop drops the piece to the bottom and locks it. It returns the number
+// of cells the piece fell, which is also reported in a drop event.
+func (s *State) HardDrop() int {
+	if s.Status != StatusRunning {
+		return 0
+	}
+
+	cells := 0
+	for {
+		next := s.CurrentPiece
+		next.Position.Y++
+
+		if s.Board.HasCollision(next) {
+			if cells > 0 {
+				drop := Drop{Hard: true, Cells: int32(cells), Points: int32(cells * hardDropPoints)} //nolint:gosec // board is 22 cells high
+				s.Score += drop.Points
+				s.emit(GameEvent{Type: "drop", Payload: drop})
+			}
+			s.lockAndSpawn()
+			s.dirty = true
+			return cells
+		}
+
+		s.fall(next)
+		cells++
+	}
+}
+
+func (s *State) Hold() {
+	if s.Status != StatusRunning || !s.canHold {
+		return
+	}
+
+	held := s.HeldPiece
+	s.HeldPiece = s.CurrentPiece.Type
+
+	if held == core.PieceNone {
+		s.setCurrentPiece(s.spawnPiece())
+	} else {
+		s.setCurrentPiece(newPiece(held))
+	}
+	s.canHold = false
+	s.dirty = true
+
+	if s.Board.HasCollision(s.CurrentPiece) {
+		s.topOut()
+	}
+}
+
+func (s *State) spawnPiece() core.Piece {
+	return newPiece(s.randomizer.Next())
+}
+
+func newPiece(t core.PieceType) core.Piece {
+	return core.Piece{
+		Type:     t,
+		Position: core.Point{X: 4, Y: 0},
+		Rotation: 0,
+	}
+}
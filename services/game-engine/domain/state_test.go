@@ -0,0 +1,513 @@
+package domain
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var simInputs = [...]Input{
+	InputNone, InputNone, InputNone, InputNone,
+	InputLeft, InputRight, InputRotateCW, InputRotateCCW,
+	InputSoftDrop, InputHardDrop, InputHold,
+}
+
+// simulate plays a game from seed with pseudo-random inputs and returns every
+// event it produced.
+func simulate(seed uint64, frames int) (*State, []GameEvent) {
+	s := NewState(DefaultRules(), seed)
+	s.Start()
+
+	inputs := rand.New(rand.NewPCG(seed, 42))
+	events := s.Drain()
+	for range frames {
+		if s.Status != StatusRunning {
+			break
+		}
+
+		input := simInputs[inputs.IntN(len(simInputs))]
+		if inputs.IntN(200) == 0 {
+			s.ReceiveGarbage(int32(1 + inputs.IntN(4))) //nolint:gosec // small value
+		}
+		events = append(events, s.Step([]Input{input})...)
+	}
+	return s, events
+}
+
+func TestState_SimulationIsDeterministic(t *testing.T) {
+	games := 1000
+	if testing.Short() {
+		games = 50
+	}
+
+	finished := 0
+	for seed := range uint64(games) {
+		first, firstEvents := simulate(seed, 3000)
+		second, secondEvents := simulate(seed, 3000)
+
+		if first.Frame != second.Frame || first.Score != second.Score || first.Lines != second.Lines {
+			t.Fatalf("seed %d: runs diverged: frame %d/%d, score %d/%d, lines %d/%d", seed,
+				first.Frame, second.Frame, first.Score, second.Score, first.Lines, second.Lines)
+		}
+		if !reflect.DeepEqual(firstEvents, secondEvents) {
+			t.Fatalf("seed %d: event streams diverged", seed)
+		}
+		if first.Status == StatusFinished {
+			finished++
+		}
+	}
+
+	if finished == 0 {
+		t.Error("no simulated game reached game over")
+	}
+}
+
+func TestState_StepAppliesInputsBeforeGravity(t *testing.T) {
+	s := NewState(DefaultRules(), 1)
+	s.Start()
+	s.Drain()
+
+	x := s.CurrentPiece.Position.X
+	events := s.Step([]Input{InputLeft, InputLeft})
+
+	if s.CurrentPiece.Position.X != x-2 {
+		t.Errorf("expected X=%d, got %d", x-2, s.CurrentPiece.Position.X)
+	}
+	if s.Frame != 1 {
+		t.Errorf("expected frame 1, got %d", s.Frame)
+	}
+	if len(events) != 1 || events[0].Type != "state_update" {
+		t.Errorf("expected a single state_update, got %v", events)
+	}
+}
+
+func TestState_GravityFallsOneCellPerSecondAtLevel1(t *testing.T) {
+	s := NewState(DefaultRules(), 1)
+	s.Start()
+
+	for range 61 {
+		s.Tick()
+	}
+
+	if s.CurrentPiece.Position.Y != 1 {
+		t.Errorf("expected Y=1 after one second, got %d", s.CurrentPiece.Position.Y)
+	}
+}
+
+func TestState_TopOutEmitsGameOverLast(t *testing.T) {
+	s := NewState(DefaultRules(), 1)
+	s.Start()
+	for y := range 2 {
+		for x := range core.BoardWidth - 1 {
+			s.Board.Set(core.Point{X: x, Y: y}, core.PieceGarbage)
+		}
+	}
+	s.Drain()
+
+	events := s.Step([]Input{InputHardDrop})
+
+	if s.Status != StatusFinished {
+		t.Fatal("expected the game to finish")
+	}
+	if last := events[len(events)-1]; last.Type != "game_over" {
+		t.Errorf("expected game_over last, got %q", last.Type)
+	}
+}
+
+type manualClock struct {
+	ticks chan time.Time
+}
+
+func (c *manualClock) Ticker(time.Duration) (<-chan time.Time, func()) {
+	return c.ticks, func() {}
+}
+
+func TestGame_ClockDrivesFrames(t *testing.T) {
+	clock := &manualClock{ticks: make(chan time.Time)}
+	game := NewGameWithRules("test-clock", DefaultRules(), 1)
+	game.Clock = clock
+	game.Start()
+	defer game.Stop()
+
+	for range 61 {
+		clock.ticks <- time.Time{}
+	}
+
+	waitFor(t, func() bool {
+		game.mu.RLock()
+		defer game.mu.RUnlock()
+		return game.Frame == 61
+	})
+
+	if y := game.GetSnapshot().CurrentPiece.Position.Y; y != 1 {
+		t.Errorf("expected Y=1 after 61 frames, got %d", y)
+	}
+}
+
+func TestGame_SuspendStopsFramesUntilResume(t *testing.T) {
+	game := NewGameWithRules("test-suspend", DefaultRules(), 1)
+	game.Clock = &manualClock{ticks: make(chan time.Time)}
+	game.Start()
+	defer game.Stop()
+
+	game.Suspend()
+	game.Tick()
+	if game.Frame != 0 {
+		t.Fatalf("suspended game must not advance, got frame %d", game.Frame)
+	}
+
+	drainEvents(game)
+	game.Resume()
+	game.Tick()
+
+	if game.Frame != 1 {
+		t.Errorf("resumed game must advance, got frame %d", game.Frame)
+	}
+	if !hasEvent(drainEvents(game), "state_update") {
+		t.Error("resume must publish a full snapshot")
+	}
+}
+
+func TestGame_WinWithFullEventBufferDoesNotBlock(t *testing.T) {
+	game := NewGameWithRules("test-full", DefaultRules(), 1)
+	game.Clock = &manualClock{ticks: make(chan time.Time)}
+	game.Start()
+	game.Suspend()
+
+	for game.Status == StatusRunning && len(game.events) < cap(game.events) {
+		game.ReceiveGarbage(1)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		game.Win()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Win blocked on a full event buffer")
+	}
+
+	var last GameEvent
+	for event := range game.Events() {
+		last = event
+	}
+	if last.Type != "winner" {
+		t.Errorf("expected winner last, got %q", last.Type)
+	}
+}
+
+func TestGame_SnapshotAcknowledgesLastSequence(t *testing.T) {
+	game := NewGameWithRules("test-ack", DefaultRules(), 1)
+	game.Clock = &manualClock{ticks: make(chan time.Time)}
+	game.Start()
+	defer game.Stop()
+	drainEvents(game)
+
+	// Push the piece against the wall so the last input changes nothing.
+	for seq := range uint64(core.BoardWidth) {
+		game.Apply(seq+1, InputLeft)
+	}
+	drainEvents(game)
+	game.Apply(core.BoardWidth+1, InputLeft)
+
+	var last GameStateDTO
+	for _, event := range drainEvents(game) {
+		if state, ok := event.Payload.(GameStateDTO); ok {
+			last = state
+		}
+	}
+	if last.LastSequence != core.BoardWidth+1 {
+		t.Errorf("expected sequence %d acknowledged, got %d", core.BoardWidth+1, last.LastSequence)
+	}
+
+	game.Tick()
+	if snapshot := game.GetSnapshot(); snapshot.Frame != 1 {
+		t.Errorf("expected frame 1 in snapshot, got %d", snapshot.Frame)
+	}
+}
+
+func TestState_StartLevel(t *testing.T) {
+	rules := DefaultRules()
+	rules.StartLevel = 5
+	s := NewState(rules, 1)
+
+	if s.Level != 5 || s.gravity != gravityPerFrame(5) {
+		t.Errorf("expected level 5 gravity, got level %d", s.Level)
+	}
+
+	s.Lines = rules.LinesPerLevel - 1
+	s.updateScore(1, core.TSpinNone)
+	if s.Level != 6 {
+		t.Errorf("expected level 6 after %d lines, got %d", rules.LinesPerLevel, s.Level)
+	}
+}
+
+func TestState_SprintEndsAtLineGoal(t *testing.T) {
+	rules := DefaultRules()
+	rules.Mode = ModeSprint
+	rules.SprintLines = 20
+	s := NewState(rules, 1)
+	s.Start()
+
+	s.Lines = 19
+	s.setCurrentPiece(newPiece(core.PieceI))
+	gap := map[int]bool{}
+	for _, m := range core.GetRotatedMinos(core.PieceI, 0) {
+		gap[s.CurrentPiece.Position.X+m.X] = true
+	}
+	for x := range core.BoardWidth {
+		if !gap[x] {
+			s.Board.Set(core.Point{X: x, Y: core.BoardHeight - 1}, core.PieceGarbage)
+		}
+	}
+	for range 10 {
+		s.Step(nil)
+	}
+
+	events := s.Step([]Input{InputHardDrop})
+
+	if s.Status != StatusFinished {
+		t.Fatal("expected the sprint to finish")
+	}
+	last := events[len(events)-1]
+	result, ok := last.Payload.(GameResult)
+	if last.Type != "result" || !ok {
+		t.Fatalf("expected result last, got %q", last.Type)
+	}
+	if result.Mode != ModeSprint || result.Lines != 20 || result.Pieces != 1 || result.Frames != 10 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ParseMode(""); err != nil || mode != ModeMarathon {
+		t.Errorf("empty mode: expected marathon, got %q, %v", mode, err)
+	}
+	if mode, err := ParseMode("sprint"); err != nil || mode != ModeSprint {
+		t.Errorf("expected sprint, got %q, %v", mode, err)
+	}
+	if _, err := ParseMode("speedrun"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	if err := ValidateSprintLines(30); err == nil {
+		t.Error("expected an error for a 30 line sprint")
+	}
+	if err := ModeZen.ValidateRandomizer(core.RandomizerNES); err == nil {
+		t.Error("zen undo needs a bag randomizer")
+	}
+}
+
+func TestState_UltraEndsWhenTimeRunsOut(t *testing.T) {
+	rules := DefaultRules()
+	rules.Mode = ModeUltra
+	rules.UltraTime = time.Second
+	s := NewState(rules, 1)
+	s.Start()
+
+	for range framesPerSecond - 1 {
+		s.Step(nil)
+	}
+	if s.Status != StatusRunning {
+		t.Fatal("ultra game ended early")
+	}
+	if remaining := s.GetSnapshot().TimeRemaining; remaining != frameDuration {
+		t.Errorf("expected one frame remaining, got %v", remaining)
+	}
+
+	events := s.Step(nil)
+
+	if s.Status != StatusFinished {
+		t.Fatal("expected the game to end when time runs out")
+	}
+	last := events[len(events)-1]
+	result, ok := last.Payload.(GameResult)
+	if last.Type != "result" || !ok || result.Reason != ReasonTimeUp {
+		t.Fatalf("expected a time_up result last, got %q %+v", last.Type, last.Payload)
+	}
+	if hasEvent(events, "game_over") {
+		t.Error("running out of time is not a top out")
+	}
+}
+
+// plugCheese fills the holes of the bottom rows of the board.
+func plugCheese(s *State, rows int) {
+	for y := core.BoardHeight - rows; y < core.BoardHeight; y++ {
+		for x := range core.BoardWidth {
+			if s.Board.Get(core.Point{X: x, Y: y}) == core.PieceNone {
+				s.Board.Set(core.Point{X: x, Y: y}, core.PieceGarbage)
+			}
+		}
+	}
+}
+
+func TestState_DigRefillsCheeseUpToGoal(t *testing.T) {
+	rules := DefaultRules()
+	rules.Mode = ModeDig
+	rules.DigLines = 18
+	s := NewState(rules, 1)
+	s.Start()
+
+	if rows := s.Board.GarbageRows(); rows != digRows {
+		t.Fatalf("expected %d garbage rows at the start, got %d", digRows, rows)
+	}
+
+	plugCheese(s, 4)
+	s.Step([]Input{InputHardDrop})
+
+	snapshot := s.GetSnapshot()
+	if snapshot.Lines != 4 || snapshot.LineGoal != 18 {
+		t.Errorf("expected 4/18 lines dug, got %d/%d", snapshot.Lines, snapshot.LineGoal)
+	}
+	if rows := s.Board.GarbageRows(); rows != digRows {
+		t.Errorf("expected the cheese refilled to %d rows, got %d", digRows, rows)
+	}
+
+	// Only 4 of the remaining 14 rows fit in the goal.
+	plugCheese(s, digRows)
+	s.Step([]Input{InputHardDrop})
+	if rows := s.Board.GarbageRows(); rows != 4 {
+		t.Errorf("expected 4 rows left to dig, got %d", rows)
+	}
+}
+
+func TestState_DigEndsAtLineGoal(t *testing.T) {
+	rules := DefaultRules()
+	rules.Mode = ModeDig
+	s := NewState(rules, 1)
+	s.Start()
+
+	plugCheese(s, DefaultDigLines)
+	events := s.Step([]Input{InputHardDrop})
+
+	if s.Status != StatusFinished {
+		t.Fatal("expected the dig to finish")
+	}
+	last := events[len(events)-1]
+	result, ok := last.Payload.(GameResult)
+	if last.Type != "result" || !ok || result.Reason != ReasonLineGoal || result.Lines != DefaultDigLines {
+		t.Errorf("expected a %d line result last, got %q %+v", DefaultDigLines, last.Type, last.Payload)
+	}
+}
+
+func newZenState() *State {
+	rules := DefaultRules()
+	rules.Mode = ModeZen
+	s := NewState(rules, 1)
+	s.Start()
+	return s
+}
+
+func TestState_ZenHasNoGravity(t *testing.T) {
+	s := newZenState()
+	y := s.CurrentPiece.Position.Y
+
+	for range 5 * framesPerSecond {
+		s.Tick()
+	}
+
+	if s.CurrentPiece.Position.Y != y {
+		t.Errorf("expected the piece to stay at Y=%d, got %d", y, s.CurrentPiece.Position.Y)
+	}
+}
+
+func TestState_ZenTopOutClearsBoard(t *testing.T) {
+	s := newZenState()
+	for y := range 2 {
+		for x := range core.BoardWidth - 1 {
+			s.Board.Set(core.Point{X: x, Y: y}, core.PieceGarbage)
+		}
+	}
+
+	s.Step([]Input{InputHardDrop})
+
+	if s.Status != StatusRunning {
+		t.Fatal("topping out must not end a zen game")
+	}
+	if !s.Board.IsEmpty() {
+		t.Error("topping out must clear the board")
+	}
+}
+
+func TestState_ZenUndoAfterTopOutKeepsClearedBoard(t *testing.T) {
+	s := newZenState()
+	for y := range 2 {
+		for x := range core.BoardWidth - 1 {
+			s.Board.Set(core.Point{X: x, Y: y}, core.PieceGarbage)
+		}
+	}
+	s.Step([]Input{InputHardDrop})
+
+	s.Step([]Input{InputHardDrop})
+	s.Step([]Input{InputUndo})
+
+	if !s.Board.IsEmpty() {
+		t.Error("undo after a top out must restore the cleared board")
+	}
+	if s.Board.HasCollision(s.CurrentPiece) {
+		t.Error("the restored piece must not collide with the board")
+	}
+}
+
+func TestState_ZenUndoRestoresLastPiece(t *testing.T) {
+	s := newZenState()
+	before := s.GetSnapshot()
+
+	s.Step([]Input{InputHold})
+	s.Step([]Input{InputLeft, InputHardDrop})
+	placed := s.GetSnapshot()
+
+	s.Step([]Input{InputUndo})
+	undone := s.GetSnapshot()
+
+	if !reflect.DeepEqual(undone.Grid, before.Grid) || undone.CurrentPiece != before.CurrentPiece ||
+		undone.HeldPiece != before.HeldPiece || !reflect.DeepEqual(undone.NextPieces, before.NextPieces) {
+		t.Errorf("undo must restore the game before the piece was placed:\ngot  %+v\nwant %+v", undone, before)
+	}
+
+	s.Step([]Input{InputHold})
+	s.Step([]Input{InputLeft, InputHardDrop})
+	if again := s.GetSnapshot(); !reflect.DeepEqual(again.Grid, placed.Grid) || !reflect.DeepEqual(again.NextPieces, placed.NextPieces) {
+		t.Error("replaying the undone piece must give the same game")
+	}
+}
+
+func TestState_UndoOnlyInZen(t *testing.T) {
+	s := NewState(DefaultRules(), 1)
+	s.Start()
+	s.Step([]Input{InputHardDrop})
+	placed := s.GetSnapshot()
+
+	s.Step([]Input{InputUndo})
+
+	if !reflect.DeepEqual(s.GetSnapshot().Grid, placed.Grid) {
+		t.Error("undo must be ignored outside zen mode")
+	}
+}
+
+func TestState_DropsAreReported(t *testing.T) {
+	s := NewState(DefaultRules(), 1)
+	s.Start()
+	s.Drain()
+
+	var drops []Drop
+	for _, event := range s.Step([]Input{InputSoftDrop, InputHardDrop}) {
+		if drop, ok := event.Payload.(Drop); ok && event.Type == "drop" {
+			drops = append(drops, drop)
+		}
+	}
+
+	if len(drops) != 2 {
+		t.Fatalf("expected a soft and a hard drop, got %v", drops)
+	}
+	if drops[0] != (Drop{Cells: 1, Points: softDropPoints}) {
+		t.Errorf("unexpected soft drop %+v", drops[0])
+	}
+	if !drops[1].Hard || drops[1].Cells == 0 || drops[1].Points != drops[1].Cells*hardDropPoints {
+		t.Errorf("unexpected hard drop %+v", drops[1])
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import "GoTetrisOnline/pkg/core"
+
+// maxUndo is how many placed pieces a zen game can take back.
+const maxUndo = 100
+
+// undoPoint is a zen game as it was when a piece spawned.
+type undoPoint struct {
+	board   []core.PieceType
+	piece   core.PieceType
+	held    core.PieceType
+	canHold bool
+	bag     core.BagState
+
+	score  int32
+	level  int32
+	lines  int32
+	pieces int32
+	combo  int32
+	b2b    int32
+}
+
+// saveUndo remembers the game at the spawn of the current piece, so it can
+// be restored once the piece is placed.
+func (s *State) saveUndo() {
+	s.spawned = nil
+	if s.Rules.Mode != ModeZen {
+		return
+	}
+
+	bag, ok := s.randomizer.(*core.Bag)
+	if !ok {
+		return
+	}
+	saved, err := bag.Snapshot()
+	if err != nil {
+		return
+	}
+
+	s.spawned = &undoPoint{
+		board:   append([]core.PieceType(nil), s.Board.Cells...),
+		piece:   s.CurrentPiece.Type,
+		held:    s.HeldPiece,
+		canHold: s.canHold,
+		bag:     saved,
+
+		score:  s.Score,
+		level:  s.Level,
+		lines:  s.Lines,
+		pieces: s.Pieces,
+		combo:  s.combo,
+		b2b:    s.b2b,
+	}
+}
+
+// pushUndo records the spawn of the piece about to be placed.
+func (s *State) pushUndo() {
+	if s.spawned == nil {
+		return
+	}
+
+	s.undo = append(s.undo, *s.spawned)
+	if len(s.undo) > maxUndo {
+		s.undo = s.undo[1:]
+	}
+}
+
+// Undo rewinds a zen game to just before the last piece was placed: the
+// board, the bag position, the hold and the score are restored and the piece
+// spawns again.
+func (s *State) Undo() {
+	if s.Status != StatusRunning || len(s.undo) == 0 {
+		return
+	}
+
+	bag, ok := s.randomizer.(*core.Bag)
+	if !ok {
+		return
+	}
+	point := s.undo[len(s.undo)-1]
+	if err := bag.Restore(point.bag); err != nil {
+		return
+	}
+	s.undo = s.undo[:len(s.undo)-1]
+
+	copy(s.Board.Cells, point.board)
+	s.HeldPiece = point.held
+	s.canHold = point.canHold
+	s.Score = point.score
+	s.setLevel(point.level)
+	s.Lines = point.lines
+	s.Pieces = point.pieces
+	s.combo = point.combo
+	s.b2b = point.b2b
+
+	s.setCurrentPiece(newPiece(point.piece))
+	s.softDropFrames = 0
+	s.spawned = &point
+	s.dirty = true
+}
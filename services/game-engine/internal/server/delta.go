@@ -0,0 +1,89 @@
+package server
+
+import (
+	"slices"
+
+	pb "GoTetrisOnline/api/proto/game/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// keyframeInterval is the number of deltas sent between two full state
+// updates, so a client that applied a delta wrongly recovers on its own.
+const keyframeInterval = 120
+
+// deltaEncoder turns the full state updates of one player on one stream
+// into deltas against the previous state sent.
+type deltaEncoder struct {
+	last   *pb.StateUpdate
+	deltas int
+}
+
+// reset makes the next state a keyframe.
+func (e *deltaEncoder) reset() {
+	e.last = nil
+}
+
+func (e *deltaEncoder) encode(state *pb.StateUpdate) *pb.ServerMessage {
+	prev := e.last
+	e.last = state
+
+	if prev != nil && e.deltas < keyframeInterval && len(prev.Grid) == len(state.Grid) {
+		delta := diffState(prev, state)
+		// After a line clear most cells change, and the full state is smaller.
+		if proto.Size(delta) < proto.Size(state) {
+			e.deltas++
+			return &pb.ServerMessage{Payload: &pb.ServerMessage_Delta{Delta: delta}}
+		}
+	}
+
+	e.deltas = 0
+	return &pb.ServerMessage{Payload: &pb.ServerMessage_State{State: state}}
+}
+
+func diffState(prev, next *pb.StateUpdate) *pb.StateDelta {
+	delta := &pb.StateDelta{
+		TickId:         next.TickId,
+		LastSequenceId: next.LastSequenceId,
+		PlayerId:       next.PlayerId,
+	}
+
+	for i := range next.Grid {
+		if prev.Grid[i] != next.Grid[i] {
+			delta.Cells = append(delta.Cells, &pb.CellChange{
+				Index: uint32(i),                  //nolint:gosec // grid indexes are small
+				Type:  pb.PieceType(next.Grid[i]), //nolint:gosec // piece types are small enums
+			})
+		}
+	}
+
+	if !proto.Equal(prev.CurrentPiece, next.CurrentPiece) {
+		delta.CurrentPiece = next.CurrentPiece
+	}
+	if !slices.Equal(prev.NextPieces, next.NextPieces) {
+		delta.NextPieces = &pb.PieceQueue{Pieces: next.NextPieces}
+	}
+	if prev.HeldPiece != next.HeldPiece {
+		delta.HeldPiece = &next.HeldPiece
+	}
+	if prev.Score != next.Score {
+		delta.Score = &next.Score
+	}
+	if prev.Level != next.Level {
+		delta.Level = &next.Level
+	}
+	if prev.PendingGarbage != next.PendingGarbage {
+		delta.PendingGarbage = &next.PendingGarbage
+	}
+	if prev.Lines != next.Lines {
+		delta.Lines = &next.Lines
+	}
+	if prev.TimeRemainingMs != next.TimeRemainingMs {
+		delta.TimeRemainingMs = &next.TimeRemainingMs
+	}
+	if prev.Paused != next.Paused {
+		delta.Paused = &next.Paused
+	}
+
+	return delta
+}
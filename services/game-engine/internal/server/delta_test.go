@@ -0,0 +1,88 @@
+package server
+
+import (
+	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/pkg/renderer"
+	"testing"
+
+	pb "GoTetrisOnline/api/proto/game/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestState() *pb.StateUpdate {
+	return &pb.StateUpdate{
+		Grid:         make([]byte, core.BoardWidth*core.BoardHeight),
+		CurrentPiece: &pb.Piece{Type: pb.PieceType_PIECE_T, X: 4},
+		NextPieces:   []pb.PieceType{pb.PieceType_PIECE_I, pb.PieceType_PIECE_O, pb.PieceType_PIECE_S},
+		Level:        1,
+		PlayerId:     "player-1",
+	}
+}
+
+func TestDeltaEncoder_RoundTrip(t *testing.T) {
+	var encoder deltaEncoder
+
+	first := newTestState()
+	msg := encoder.encode(first)
+	if msg.GetState() == nil {
+		t.Fatal("first state on a stream must be a keyframe")
+	}
+	client := msg.GetState()
+
+	second := newTestState()
+	second.TickId = 5
+	second.CurrentPiece.Y = 3
+	second.Grid[len(second.Grid)-1] = byte(core.PieceGarbage)
+	second.Score = 100
+
+	msg = encoder.encode(second)
+	delta := msg.GetDelta()
+	if delta == nil {
+		t.Fatal("expected a delta")
+	}
+	if len(delta.Cells) != 1 || delta.NextPieces != nil || delta.Level != nil {
+		t.Errorf("delta must only carry what changed, got %v", delta)
+	}
+
+	client, err := renderer.ApplyDelta(client, delta)
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if !proto.Equal(client, second) {
+		t.Errorf("applied delta does not match the server state:\ngot  %v\nwant %v", client, second)
+	}
+}
+
+func TestDeltaEncoder_SendsKeyframes(t *testing.T) {
+	var encoder deltaEncoder
+	encoder.encode(newTestState())
+
+	for i := range keyframeInterval {
+		if msg := encoder.encode(newTestState()); msg.GetDelta() == nil {
+			t.Fatalf("message %d: expected a delta", i)
+		}
+	}
+	if msg := encoder.encode(newTestState()); msg.GetState() == nil {
+		t.Error("expected a keyframe after keyframeInterval deltas")
+	}
+
+	encoder.reset()
+	if msg := encoder.encode(newTestState()); msg.GetState() == nil {
+		t.Error("expected a keyframe after reset")
+	}
+}
+
+func TestDeltaEncoder_FullStateWhenSmaller(t *testing.T) {
+	var encoder deltaEncoder
+	encoder.encode(newTestState())
+
+	cleared := newTestState()
+	for i := range cleared.Grid {
+		cleared.Grid[i] = byte(core.PieceGarbage)
+	}
+
+	if msg := encoder.encode(cleared); msg.GetState() == nil {
+		t.Error("expected a full state when most cells changed")
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	pb "GoTetrisOnline/api/proto/game/v1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errIdle = status.Error(codes.DeadlineExceeded, "no message from client within the idle timeout")
+
+// touch records that the client of the session was heard from.
+func (s *session) touch(now time.Time) {
+	s.lastSeen.Store(now.UnixNano())
+}
+
+// observePing measures the round trip of the pong echoed by ping, if any.
+func (s *session) observePing(ping *pb.PingRequest, now time.Time) {
+	if ping.ServerTimestamp == 0 {
+		return
+	}
+
+	rtt := now.Sub(time.Unix(0, ping.ServerTimestamp)) - time.Duration(ping.EchoDelay)
+	if rtt >= 0 {
+		s.rtt.Store(int64(rtt))
+	}
+}
+
+// RTT returns the player's last measured round trip time.
+func (s *session) RTT() time.Duration {
+	return time.Duration(s.rtt.Load())
+}
+
+// watchIdle returns errIdle once the client has been silent for longer than
+// timeout.
+func (s *session) watchIdle(ctx context.Context, timeout time.Duration) error {
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if now.Sub(time.Unix(0, s.lastSeen.Load())) > timeout {
+				return errIdle
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "GoTetrisOnline/api/proto/game/v1"
+)
+
+func TestSession_ObservePingMeasuresRoundTrip(t *testing.T) {
+	var sess session
+	sent := time.Unix(100, 0)
+
+	sess.observePing(&pb.PingRequest{}, sent)
+	if sess.RTT() != 0 {
+		t.Error("a ping without an echoed pong must not change the RTT")
+	}
+
+	// The client held the pong for 900ms before pinging again.
+	sess.observePing(&pb.PingRequest{
+		ServerTimestamp: sent.UnixNano(),
+		EchoDelay:       int64(900 * time.Millisecond),
+	}, sent.Add(time.Second))
+
+	if rtt := sess.RTT(); rtt != 100*time.Millisecond {
+		t.Errorf("expected 100ms, got %v", rtt)
+	}
+}
+
+func TestSession_WatchIdle(t *testing.T) {
+	var sess session
+	sess.touch(time.Now())
+
+	err := sess.watchIdle(context.Background(), 20*time.Millisecond)
+	if !errors.Is(err, errIdle) {
+		t.Errorf("expected errIdle, got %v", err)
+	}
+
+	sess.touch(time.Now())
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				sess.touch(now)
+			}
+		}
+	}()
+
+	if err := sess.watchIdle(ctx, 100*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("an active client must not time out, got %v", err)
+	}
+}
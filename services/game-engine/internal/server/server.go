@@ -1,8 +1,17 @@
 package server
 
 import (
+	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/services/game-engine/auth"
 	"GoTetrisOnline/services/game-engine/domain"
+	"GoTetrisOnline/services/game-engine/wire"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	pb "GoTetrisOnline/api/proto/game/v1"
 
@@ -14,12 +23,39 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const playersPerMatch = 2
+
+// Options configures a GrpcServer.
+type Options struct {
+	// ReplayDir receives replays of finished games. Empty disables replays.
+	ReplayDir string
+	// IdleTimeout drops Play streams that send nothing for that long. Zero
+	// disables the timeout.
+	IdleTimeout time.Duration
+	// ResumeGrace is how long a dropped player's game waits for a resume.
+	ResumeGrace time.Duration
+	// Rules are the defaults for new games.
+	Rules domain.Rules
+}
+
 type GrpcServer struct {
 	pb.UnimplementedGameServiceServer
+
+	matches  *domain.MatchRegistry
+	sessions *sessionRegistry
+	auth     auth.Authenticator
+	opts     Options
 }
 
-func NewGrpcServer() *GrpcServer {
-	return &GrpcServer{}
+// NewGrpcServer creates the game service. Join tokens are checked with
+// authenticator.
+func NewGrpcServer(authenticator auth.Authenticator, opts Options) *GrpcServer {
+	return &GrpcServer{
+		matches:  domain.NewMatchRegistry(playersPerMatch),
+		sessions: newSessionRegistry(opts.ResumeGrace),
+		auth:     authenticator,
+		opts:     opts,
+	}
 }
 
 func (s *GrpcServer) Play(stream pb.GameService_PlayServer) error {
@@ -33,72 +69,367 @@ func (s *GrpcServer) Play(stream pb.GameService_PlayServer) error {
 		return status.Error(codes.InvalidArgument, "first message must be JoinRequest")
 	}
 
-	matchID := joinReq.Join.MatchId
-	log.Printf("Player joining match %s", matchID)
+	sess, err := s.attach(joinReq.Join)
+	if err != nil {
+		return err
+	}
+	game := sess.game
+
+	left := false
+	defer func() {
+		s.sessions.detach(sess, left, func() { s.leave(sess) })
+	}()
 
-	game := domain.NewGame(matchID)
-	game.Start()
+	if err := stream.Send(&pb.ServerMessage{
+		Payload: &pb.ServerMessage_Session{
+			Session: &pb.SessionInfo{
+				ResumeToken: sess.token,
+				PlayerId:    game.UID,
+				MatchId:     sess.matchID,
+			},
+		},
+	}); err != nil {
+		return err
+	}
 
 	g, ctx := errgroup.WithContext(stream.Context())
+	resync := make(chan struct{}, 1)
+	pongs := make(chan *pb.PongResponse, 4)
 
 	g.Go(func() error {
-		defer game.Stop()
+		var encoder deltaEncoder
+		send := func(event domain.GameEvent) error {
+			protoMsg := mapEventToProto(event)
+			if protoMsg == nil {
+				return nil
+			}
+			tagPlayer(protoMsg, game.UID, game.Name)
+
+			if state := protoMsg.GetState(); state != nil {
+				protoMsg = encoder.encode(state)
+			}
+			return stream.Send(protoMsg)
+		}
 
 		for {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
+			case <-resync:
+				encoder.reset()
+				if err := send(domain.GameEvent{Type: "state_update", Payload: game.GetSnapshot()}); err != nil {
+					return err
+				}
+			case pong := <-pongs:
+				pong.ServerTimestamp = time.Now().UnixNano()
+				if err := stream.Send(&pb.ServerMessage{Payload: &pb.ServerMessage_Pong{Pong: pong}}); err != nil {
+					return err
+				}
 			case event, ok := <-game.Events():
 				if !ok {
 					return nil
 				}
-
-				protoMsg := mapEventToProto(event)
-				if protoMsg == nil {
-					continue
-				}
-
-				if err := stream.Send(protoMsg); err != nil {
+				if err := send(event); err != nil {
 					return err
 				}
 			}
 		}
 	})
 
+	// Recv cannot be interrupted, so the receive loop runs outside the group
+	// and ends once Play returns and the stream is torn down.
+	sess.touch(time.Now())
+	received := make(chan error, 1)
+	go func() {
+		received <- receive(stream, sess, resync, pongs)
+	}()
+
 	g.Go(func() error {
-		for {
-			in, err := stream.Recv()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-received:
 			if errors.Is(err, io.EOF) {
+				left = true
 				return nil
 			}
-			if err != nil {
-				return err
+			return err
+		}
+	})
+
+	if s.opts.IdleTimeout > 0 {
+		g.Go(func() error {
+			return sess.watchIdle(ctx, s.opts.IdleTimeout)
+		})
+	}
+
+	err = g.Wait()
+	if errors.Is(err, errIdle) {
+		log.Printf("Player %q idle for %v, dropping stream", game.UID, s.opts.IdleTimeout)
+	}
+	return err
+}
+
+// receive handles client messages until the stream ends.
+func receive(stream pb.GameService_PlayServer, sess *session, resync chan<- struct{}, pongs chan<- *pb.PongResponse) error {
+	for {
+		in, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		sess.touch(now)
+
+		switch payload := in.Payload.(type) {
+		case *pb.ClientMessage_Input:
+			handleInput(sess, payload.Input)
+		case *pb.ClientMessage_Resync:
+			select {
+			case resync <- struct{}{}:
+			default:
 			}
+		case *pb.ClientMessage_Ping:
+			sess.observePing(payload.Ping, now)
+			select {
+			case pongs <- &pb.PongResponse{Timestamp: payload.Ping.Timestamp}:
+			default:
+			}
+		}
+	}
+}
 
-			handleInput(game, in.GetInput())
+// attach starts a new session for a JoinRequest, or picks up a dropped one
+// when the request carries a resume token.
+func (s *GrpcServer) attach(join *pb.JoinRequest) (*session, error) {
+	if join.ResumeToken != "" {
+		sess, err := s.sessions.resume(join.ResumeToken)
+		switch {
+		case errors.Is(err, errUnknownSession):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case err != nil:
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
 		}
+
+		log.Printf("Player %q resumed match %s", sess.game.UID, sess.matchID)
+		return sess, nil
+	}
+
+	identity, err := s.auth.Authenticate(join.Token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	matchID := join.MatchId
+
+	rules := s.opts.Rules
+	rules.Mode, err = domain.ParseMode(join.Mode)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if join.SprintLines != 0 {
+		if err := domain.ValidateSprintLines(join.SprintLines); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		rules.SprintLines = join.SprintLines
+	}
+	if join.DigLines != 0 {
+		if err := domain.ValidateDigLines(join.DigLines); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		rules.DigLines = join.DigLines
+	}
+	rules.CleanCheese = join.CleanCheese
+	if join.Randomizer != "" {
+		randomizer, err := core.ParseRandomizerKind(join.Randomizer)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		rules.Randomizer = randomizer
+	}
+	if err := rules.Mode.ValidateRandomizer(rules.Randomizer); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Signed tokens carry their own name; anonymous players pick one.
+	name := identity.DisplayName
+	if name == "" {
+		name = displayName(join.Name)
+	}
+
+	match, game, err := s.matches.Join(matchID, rules, domain.Player{
+		ID:   identity.PlayerID,
+		Name: name,
 	})
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	log.Printf("Player %q joined match %s", game.UID, matchID)
+
+	go func() {
+		<-game.Done()
+		s.saveReplay(matchID, game)
+	}()
+
+	return s.sessions.create(matchID, match, game), nil
+}
+
+// leave removes the player of an expired session from its match.
+func (s *GrpcServer) leave(sess *session) {
+	sess.match.Leave(sess.game)
+
+	log.Printf("Player %q left match %s (rtt %v)", sess.game.UID, sess.matchID, sess.RTT())
 
-	return g.Wait()
+	if winner, ok := sess.match.Result(); ok {
+		log.Printf("Match %s won by %s (%s)", sess.matchID, winner.Name, winner.ID)
+	}
 }
 
-func handleInput(game *domain.Game, input *pb.InputRequest) {
+// Spectate streams the state of every player in a running match. Each
+// message is tagged with the id of the player it belongs to.
+func (s *GrpcServer) Spectate(req *pb.SpectateRequest, stream pb.GameService_SpectateServer) error {
+	match, ok := s.matches.Get(req.MatchId)
+	if !ok {
+		return status.Errorf(codes.NotFound, "match %q not found", req.MatchId)
+	}
+
+	spectator, err := match.Spectate()
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	defer match.Unspectate(spectator)
+
+	log.Printf("Spectator watching match %s", req.MatchId)
+
+	// Deltas are encoded per player, since the boards interleave on the
+	// stream.
+	encoders := make(map[string]*deltaEncoder)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-spectator.Events():
+			if !ok {
+				return nil
+			}
+
+			protoMsg := mapEventToProto(event.Event)
+			if protoMsg == nil {
+				continue
+			}
+			tagPlayer(protoMsg, event.Player, event.Name)
+
+			if state := protoMsg.GetState(); state != nil {
+				encoder, ok := encoders[event.Player]
+				if !ok {
+					encoder = &deltaEncoder{}
+					encoders[event.Player] = encoder
+				}
+				protoMsg = encoder.encode(state)
+			}
+
+			if err := stream.Send(protoMsg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func tagPlayer(msg *pb.ServerMessage, playerID, playerName string) {
+	switch payload := msg.Payload.(type) {
+	case *pb.ServerMessage_State:
+		payload.State.PlayerId = playerID
+	case *pb.ServerMessage_Event:
+		if payload.Event.Metadata == nil {
+			payload.Event.Metadata = make(map[string]string)
+		}
+		if _, ok := payload.Event.Metadata["player_id"]; !ok {
+			payload.Event.Metadata["player_id"] = playerID
+			payload.Event.Metadata["player_name"] = playerName
+		}
+	}
+}
+
+func (s *GrpcServer) saveReplay(matchID string, game *domain.Game) {
+	if s.opts.ReplayDir == "" {
+		return
+	}
+
+	replay := game.Replay()
+	if replay.Frames == 0 {
+		// The match never started.
+		return
+	}
+	replay.Match = matchID
+
+	name := fmt.Sprintf("%s-%s-%d.json", replayFileName(matchID), game.UID, time.Now().Unix())
+	if err := replay.Save(filepath.Join(s.opts.ReplayDir, name)); err != nil {
+		log.Printf("failed to save replay: %v", err)
+	}
+}
+
+// maxNameLength limits the display names players choose for themselves.
+const maxNameLength = 24
+
+// displayName trims a client supplied name and strips control characters.
+func displayName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+	return name
+}
+
+// replayFileName keeps only characters that are safe in a file name, since
+// match ids come from clients.
+func replayFileName(matchID string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, matchID)
+}
+
+var inputs = map[pb.InputType]domain.Input{
+	pb.InputType_INPUT_LEFT:       domain.InputLeft,
+	pb.InputType_INPUT_RIGHT:      domain.InputRight,
+	pb.InputType_INPUT_ROTATE_CW:  domain.InputRotateCW,
+	pb.InputType_INPUT_ROTATE_CCW: domain.InputRotateCCW,
+	pb.InputType_INPUT_SOFT_DROP:  domain.InputSoftDrop,
+	pb.InputType_INPUT_HARD_DROP:  domain.InputHardDrop,
+	pb.InputType_INPUT_HOLD:       domain.InputHold,
+	pb.InputType_INPUT_UNDO:       domain.InputUndo,
+}
+
+func handleInput(sess *session, input *pb.InputRequest) {
 	if input == nil {
 		return
 	}
-	switch input.Input {
-	case pb.InputType_INPUT_LEFT:
-		game.MoveLeft()
-	case pb.InputType_INPUT_RIGHT:
-		game.MoveRight()
-	case pb.InputType_INPUT_ROTATE_CW:
-		game.Rotate(1)
-	case pb.InputType_INPUT_ROTATE_CCW:
-		game.Rotate(-1)
-	case pb.InputType_INPUT_HARD_DROP:
-		game.HardDrop()
+
+	game := sess.game
+	if input.Input == pb.InputType_INPUT_PAUSE {
+		if game.IsPaused() {
+			sess.match.Unpause(game)
+		} else {
+			sess.match.RequestPause(game)
+		}
+		return
+	}
+
+	if in, ok := inputs[input.Input]; ok {
+		game.Apply(input.SequenceId, in)
 	}
 }
+
 func mapEventToProto(event domain.GameEvent) *pb.ServerMessage {
 	switch event.Type {
 	case "state_update":
@@ -107,38 +438,140 @@ func mapEventToProto(event domain.GameEvent) *pb.ServerMessage {
 			return nil
 		}
 
-		nextPieces := make([]pb.PieceType, len(state.NextPieces))
-		for i, pieceType := range state.NextPieces {
-			nextPieces[i] = pb.PieceType(pieceType) //nolint:gosec // piece types are small enums
-		}
-
 		return &pb.ServerMessage{
-			Payload: &pb.ServerMessage_State{
-				State: &pb.StateUpdate{
-					Score: state.Score,
-					Level: state.Level,
-					Grid:  state.Grid,
-
-					CurrentPiece: &pb.Piece{
-						Type:     pb.PieceType(state.CurrentPiece.Type), //nolint:gosec // coordinates are small
-						X:        int32(state.CurrentPiece.Position.X),  //nolint:gosec // coordinates are small
-						Y:        int32(state.CurrentPiece.Position.Y),  //nolint:gosec // coordinates are small
-						Rotation: int32(state.CurrentPiece.Rotation),    //nolint:gosec // coordinates are small
-					},
-					NextPieces: nextPieces,
-				},
-			},
+			Payload: &pb.ServerMessage_State{State: wire.StateUpdate(state)},
 		}
 
 	case "game_over":
-		return &pb.ServerMessage{
-			Payload: &pb.ServerMessage_Event{
-				Event: &pb.GameEvent{
-					Type:    pb.EventType_EVENT_GAME_OVER,
-					Message: "Game Over",
-				},
-			},
+		return newEventMessage(pb.EventType_EVENT_GAME_OVER, "Game Over", nil)
+
+	case "result":
+		result, ok := event.Payload.(domain.GameResult)
+		if !ok {
+			return nil
 		}
+		return newEventMessage(pb.EventType_EVENT_RESULT, resultMessage(result), map[string]string{
+			"mode":    string(result.Mode),
+			"reason":  string(result.Reason),
+			"frames":  strconv.FormatUint(result.Frames, 10),
+			"time_ms": strconv.FormatInt(result.Duration().Milliseconds(), 10),
+			"pieces":  strconv.Itoa(int(result.Pieces)),
+			"pps":     strconv.FormatFloat(result.PPS(), 'f', 2, 64),
+			"lines":   strconv.Itoa(int(result.Lines)),
+			"score":   strconv.Itoa(int(result.Score)),
+		})
+
+	case "match_start":
+		players, ok := event.Payload.(int32)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_MATCH_START, "Match Start", map[string]string{
+			"players": strconv.Itoa(int(players)),
+		})
+
+	case "garbage_received":
+		lines, ok := event.Payload.(int32)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_GARBAGE_RECEIVED, fmt.Sprintf("+%d GARBAGE", lines), map[string]string{
+			"lines": strconv.Itoa(int(lines)),
+		})
+
+	case "winner":
+		player, ok := event.Payload.(domain.Player)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_WINNER, "Winner: "+player.Name, map[string]string{
+			"player_id":   player.ID,
+			"player_name": player.Name,
+		})
+
+	case "paused", "resumed", "pause_requested":
+		player, ok := event.Payload.(domain.Player)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pauseEvents[event.Type], pauseMessages[event.Type]+player.Name, map[string]string{
+			"by_id":   player.ID,
+			"by_name": player.Name,
+		})
+
+	case "drop":
+		drop, ok := event.Payload.(domain.Drop)
+		if !ok {
+			return nil
+		}
+		kind := "soft"
+		if drop.Hard {
+			kind = "hard"
+		}
+		return newEventMessage(pb.EventType_EVENT_DROP, fmt.Sprintf("+%d", drop.Points), map[string]string{
+			"kind":   kind,
+			"cells":  strconv.Itoa(int(drop.Cells)),
+			"points": strconv.Itoa(int(drop.Points)),
+		})
+
+	case "combo":
+		count, ok := event.Payload.(int32)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_COMBO, fmt.Sprintf("%d COMBO", count), map[string]string{
+			"count": strconv.Itoa(int(count)),
+		})
+
+	case "back_to_back":
+		count, ok := event.Payload.(int32)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_BACK_TO_BACK, "B2B", map[string]string{
+			"count": strconv.Itoa(int(count)),
+		})
+
+	case "perfect_clear":
+		lines, ok := event.Payload.(int32)
+		if !ok {
+			return nil
+		}
+		return newEventMessage(pb.EventType_EVENT_PERFECT_CLEAR, "ALL CLEAR", map[string]string{
+			"lines": strconv.Itoa(int(lines)),
+		})
 	}
 	return nil
 }
+
+var (
+	pauseEvents = map[string]pb.EventType{
+		"paused":          pb.EventType_EVENT_PAUSED,
+		"resumed":         pb.EventType_EVENT_RESUMED,
+		"pause_requested": pb.EventType_EVENT_PAUSE_REQUESTED,
+	}
+	pauseMessages = map[string]string{
+		"paused":          "Paused by ",
+		"resumed":         "Resumed by ",
+		"pause_requested": "Pause requested by ",
+	}
+)
+
+func resultMessage(result domain.GameResult) string {
+	if result.Reason == domain.ReasonTimeUp {
+		return fmt.Sprintf("Time up: %d points, %d lines (%.2f PPS)", result.Score, result.Lines, result.PPS())
+	}
+	return fmt.Sprintf("%d lines in %.3fs (%.2f PPS)", result.Lines, result.Duration().Seconds(), result.PPS())
+}
+
+func newEventMessage(eventType pb.EventType, message string, metadata map[string]string) *pb.ServerMessage {
+	return &pb.ServerMessage{
+		Payload: &pb.ServerMessage_Event{
+			Event: &pb.GameEvent{
+				Type:     eventType,
+				Message:  message,
+				Metadata: metadata,
+			},
+		},
+	}
+}
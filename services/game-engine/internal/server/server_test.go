@@ -2,8 +2,12 @@ package server
 
 import (
 	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/services/game-engine/auth"
 	"GoTetrisOnline/services/game-engine/domain"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	pb "GoTetrisOnline/api/proto/game/v1"
 )
@@ -21,6 +25,7 @@ func TestMapEventToProto_StateUpdate_IncludesNextPieces(t *testing.T) {
 			Rotation: 1,
 		},
 		NextPieces: nextPieces,
+		HeldPiece:  core.PieceS,
 	}
 
 	event := domain.GameEvent{
@@ -60,6 +65,10 @@ func TestMapEventToProto_StateUpdate_IncludesNextPieces(t *testing.T) {
 		}
 	}
 
+	if state.HeldPiece != pb.PieceType_PIECE_S {
+		t.Errorf("Expected HeldPiece S, got %v", state.HeldPiece)
+	}
+
 	if state.CurrentPiece.Type != pb.PieceType_PIECE_J {
 		t.Errorf("Expected CurrentPiece type J, got %v", state.CurrentPiece.Type)
 	}
@@ -156,3 +165,167 @@ func TestMapEventToProto_InvalidPayloadType(t *testing.T) {
 		t.Errorf("Expected nil for invalid payload type, got %+v", protoMsg)
 	}
 }
+
+func TestMapEventToProto_Combo(t *testing.T) {
+	event := domain.GameEvent{
+		Type:    "combo",
+		Payload: int32(3),
+	}
+
+	protoMsg := mapEventToProto(event)
+
+	if protoMsg == nil {
+		t.Fatal("mapEventToProto returned nil")
+	}
+
+	gameEvent, ok := protoMsg.Payload.(*pb.ServerMessage_Event)
+	if !ok {
+		t.Fatalf("Expected ServerMessage_Event, got %T", protoMsg.Payload)
+	}
+
+	if gameEvent.Event.Type != pb.EventType_EVENT_COMBO {
+		t.Errorf("Expected EVENT_COMBO, got %v", gameEvent.Event.Type)
+	}
+
+	if gameEvent.Event.Message != "3 COMBO" {
+		t.Errorf("Expected '3 COMBO', got '%s'", gameEvent.Event.Message)
+	}
+
+	if gameEvent.Event.Metadata["count"] != "3" {
+		t.Errorf("Expected count=3, got '%s'", gameEvent.Event.Metadata["count"])
+	}
+}
+
+func TestMapEventToProto_Drop(t *testing.T) {
+	protoMsg := mapEventToProto(domain.GameEvent{Type: "drop", Payload: domain.Drop{Hard: true, Cells: 18, Points: 36}})
+
+	event := protoMsg.GetEvent()
+	if event.GetType() != pb.EventType_EVENT_DROP {
+		t.Fatalf("expected EVENT_DROP, got %v", protoMsg)
+	}
+	if event.Metadata["kind"] != "hard" || event.Metadata["cells"] != "18" || event.Metadata["points"] != "36" {
+		t.Errorf("unexpected metadata %v", event.Metadata)
+	}
+}
+
+func TestMapEventToProto_PerfectClear(t *testing.T) {
+	protoMsg := mapEventToProto(domain.GameEvent{Type: "perfect_clear", Payload: int32(4)})
+
+	if protoMsg == nil {
+		t.Fatal("mapEventToProto returned nil")
+	}
+
+	gameEvent := protoMsg.Payload.(*pb.ServerMessage_Event)
+	if gameEvent.Event.Type != pb.EventType_EVENT_PERFECT_CLEAR {
+		t.Errorf("Expected EVENT_PERFECT_CLEAR, got %v", gameEvent.Event.Type)
+	}
+}
+
+func TestMapEventToProto_Result(t *testing.T) {
+	result := domain.GameResult{Mode: domain.ModeSprint, Frames: 3600, Pieces: 90, Lines: 40}
+	protoMsg := mapEventToProto(domain.GameEvent{Type: "result", Payload: result})
+
+	if protoMsg == nil {
+		t.Fatal("mapEventToProto returned nil")
+	}
+
+	event := protoMsg.Payload.(*pb.ServerMessage_Event).Event
+	if event.Type != pb.EventType_EVENT_RESULT {
+		t.Errorf("Expected EVENT_RESULT, got %v", event.Type)
+	}
+	if event.Message != "40 lines in 60.000s (1.50 PPS)" {
+		t.Errorf("unexpected message %q", event.Message)
+	}
+	if event.Metadata["time_ms"] != "60000" || event.Metadata["pps"] != "1.50" || event.Metadata["mode"] != "sprint" {
+		t.Errorf("unexpected metadata %v", event.Metadata)
+	}
+}
+
+func TestResultMessage_TimeUp(t *testing.T) {
+	result := domain.GameResult{Mode: domain.ModeUltra, Reason: domain.ReasonTimeUp, Frames: 7200, Pieces: 240, Lines: 60, Score: 52000}
+
+	if got, want := resultMessage(result), "Time up: 52000 points, 60 lines (2.00 PPS)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMapEventToProto_Paused(t *testing.T) {
+	protoMsg := mapEventToProto(domain.GameEvent{Type: "paused", Payload: domain.Player{ID: "p1", Name: "Alice"}})
+
+	if protoMsg == nil {
+		t.Fatal("mapEventToProto returned nil")
+	}
+
+	event := protoMsg.Payload.(*pb.ServerMessage_Event).Event
+	if event.Type != pb.EventType_EVENT_PAUSED || event.Message != "Paused by Alice" {
+		t.Errorf("unexpected event %v %q", event.Type, event.Message)
+	}
+	if event.Metadata["by_id"] != "p1" {
+		t.Errorf("expected by_id p1, got %v", event.Metadata)
+	}
+}
+
+func TestReplayFileName_StripsPathSeparators(t *testing.T) {
+	if name := replayFileName("../room 1/x"); name != "___room_1_x" {
+		t.Errorf("unexpected file name %q", name)
+	}
+}
+
+func TestGrpcServer_SavesReplayWhenGameEnds(t *testing.T) {
+	dir := t.TempDir()
+	s := NewGrpcServer(auth.Anonymous{}, Options{ReplayDir: dir, ResumeGrace: time.Minute, Rules: domain.DefaultRules()})
+
+	sess, err := s.attach(&pb.JoinRequest{MatchId: "room-1", Mode: string(domain.ModeZen)})
+	if err != nil {
+		t.Fatalf("attach failed: %v", err)
+	}
+	for deadline := time.Now().Add(time.Second); sess.game.GetSnapshot().Frame == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("game did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The session stays attached, so only the end of the game can write it.
+	sess.game.Stop()
+
+	for deadline := time.Now().Add(time.Second); ; {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected one replay after the game ended, got %d files", len(entries))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTagPlayer(t *testing.T) {
+	state := mapEventToProto(domain.GameEvent{Type: "state_update", Payload: domain.GameStateDTO{}})
+	tagPlayer(state, "player-1", "Player 1")
+	if state.GetState().PlayerId != "player-1" {
+		t.Errorf("expected state tagged with player-1, got %q", state.GetState().PlayerId)
+	}
+
+	winner := mapEventToProto(domain.GameEvent{Type: "winner", Payload: domain.Player{ID: "player-2", Name: "Bob"}})
+	tagPlayer(winner, "player-1", "Player 1")
+	if id := winner.GetEvent().Metadata["player_id"]; id != "player-2" {
+		t.Errorf("existing player id must be kept, got %q", id)
+	}
+	if name := winner.GetEvent().Metadata["player_name"]; name != "Bob" {
+		t.Errorf("expected winner name Bob, got %q", name)
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	if got := displayName("  Alice\x1b[31m "); got != "Alice[31m" {
+		t.Errorf("expected control characters and spaces stripped, got %q", got)
+	}
+	if got := displayName(strings.Repeat("é", 40)); len([]rune(got)) != maxNameLength {
+		t.Errorf("expected %d runes, got %d", maxNameLength, len([]rune(got)))
+	}
+}
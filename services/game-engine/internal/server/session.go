@@ -0,0 +1,115 @@
+package server
+
+import (
+	"GoTetrisOnline/services/game-engine/domain"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	errUnknownSession  = errors.New("unknown or expired resume token")
+	errSessionAttached = errors.New("session is still attached to another stream")
+)
+
+// session ties a player's game to a resume token so a dropped stream can
+// pick the game up again.
+type session struct {
+	token   string
+	matchID string
+	match   *domain.Match
+	game    *domain.Game
+
+	attached bool
+	expiry   *time.Timer
+
+	// lastSeen (unix nanoseconds) and rtt are updated by the stream's
+	// receive loop and read by its idle watchdog.
+	lastSeen atomic.Int64
+	rtt      atomic.Int64
+}
+
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	grace    time.Duration
+}
+
+func newSessionRegistry(grace time.Duration) *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[string]*session),
+		grace:    grace,
+	}
+}
+
+func (r *sessionRegistry) create(matchID string, match *domain.Match, game *domain.Game) *session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := &session{
+		token:    newResumeToken(),
+		matchID:  matchID,
+		match:    match,
+		game:     game,
+		attached: true,
+	}
+	r.sessions[s.token] = s
+	return s
+}
+
+// resume reattaches a detached session and resumes its game.
+func (r *sessionRegistry) resume(token string) (*session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.sessions[token]
+	if !ok {
+		return nil, errUnknownSession
+	}
+	if s.attached {
+		return nil, errSessionAttached
+	}
+
+	s.expiry.Stop()
+	s.attached = true
+	s.game.Resume()
+	return s, nil
+}
+
+// detach suspends the session's game and calls expire unless the player
+// resumes within the grace period. Games the player left on purpose or that
+// already finished expire right away.
+func (r *sessionRegistry) detach(s *session, left bool, expire func()) {
+	r.mu.Lock()
+	s.attached = false
+	if left || r.grace <= 0 || s.game.IsFinished() {
+		delete(r.sessions, s.token)
+		r.mu.Unlock()
+
+		expire()
+		return
+	}
+
+	s.game.Suspend()
+	s.expiry = time.AfterFunc(r.grace, func() {
+		r.mu.Lock()
+		if s.attached || r.sessions[s.token] != s {
+			r.mu.Unlock()
+			return
+		}
+		delete(r.sessions, s.token)
+		r.mu.Unlock()
+
+		expire()
+	})
+	r.mu.Unlock()
+}
+
+func newResumeToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"GoTetrisOnline/services/game-engine/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestSession(t *testing.T, r *sessionRegistry) *session {
+	t.Helper()
+
+	match := domain.NewMatch("room-1", 1, domain.DefaultRules())
+	game, err := match.Join(domain.Player{})
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	t.Cleanup(game.Stop)
+
+	return r.create("room-1", match, game)
+}
+
+func TestSessionRegistry_ResumeReattachesSameGame(t *testing.T) {
+	r := newSessionRegistry(time.Minute)
+	sess := newTestSession(t, r)
+
+	if _, err := r.resume(sess.token); !errors.Is(err, errSessionAttached) {
+		t.Fatalf("attached session must not be resumed, got %v", err)
+	}
+
+	r.detach(sess, false, func() { t.Error("session expired during grace period") })
+
+	resumed, err := r.resume(sess.token)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if resumed.game != sess.game {
+		t.Error("resume must reattach the same game")
+	}
+	if !resumed.game.IsRunning() {
+		t.Error("resumed game must keep running")
+	}
+}
+
+func TestSessionRegistry_ExpiresAfterGrace(t *testing.T) {
+	r := newSessionRegistry(10 * time.Millisecond)
+	sess := newTestSession(t, r)
+
+	expired := make(chan struct{})
+	r.detach(sess, false, func() { close(expired) })
+
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatal("session did not expire")
+	}
+
+	if _, err := r.resume(sess.token); !errors.Is(err, errUnknownSession) {
+		t.Errorf("expected errUnknownSession, got %v", err)
+	}
+}
+
+func TestSessionRegistry_LeavingExpiresImmediately(t *testing.T) {
+	r := newSessionRegistry(time.Minute)
+	sess := newTestSession(t, r)
+
+	expired := false
+	r.detach(sess, true, func() { expired = true })
+
+	if !expired {
+		t.Error("a player leaving on purpose must not get a grace period")
+	}
+}
@@ -0,0 +1,42 @@
+// Package wire converts game-engine domain values to their protobuf messages.
+package wire
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/services/game-engine/domain"
+)
+
+// StateUpdate converts a game snapshot into the message sent to clients.
+func StateUpdate(state domain.GameStateDTO) *pb.StateUpdate {
+	nextPieces := make([]pb.PieceType, len(state.NextPieces))
+	for i, pieceType := range state.NextPieces {
+		nextPieces[i] = pb.PieceType(pieceType) //nolint:gosec // piece types are small enums
+	}
+
+	return &pb.StateUpdate{
+		TickId:         state.Frame,
+		LastSequenceId: state.LastSequence,
+
+		Score: state.Score,
+		Level: state.Level,
+		Grid:  state.Grid,
+
+		CurrentPiece: &pb.Piece{
+			Type:     pb.PieceType(state.CurrentPiece.Type), //nolint:gosec // coordinates are small
+			X:        int32(state.CurrentPiece.Position.X),  //nolint:gosec // coordinates are small
+			Y:        int32(state.CurrentPiece.Position.Y),  //nolint:gosec // coordinates are small
+			Rotation: int32(state.CurrentPiece.Rotation),    //nolint:gosec // coordinates are small
+		},
+		NextPieces: nextPieces,
+		HeldPiece:  pb.PieceType(state.HeldPiece), //nolint:gosec // piece types are small enums
+
+		PendingGarbage: state.PendingGarbage,
+
+		Mode:     string(state.Mode),
+		Lines:    state.Lines,
+		LineGoal: state.LineGoal,
+
+		TimeRemainingMs: state.TimeRemaining.Milliseconds(),
+		Paused:          state.Paused,
+	}
+}
@@ -1,26 +1,46 @@
 package main
 
 import (
+	"GoTetrisOnline/pkg/config"
 	"GoTetrisOnline/services/gateway/internal/handler"
+	"flag"
 	"log"
 	"net/http"
-	"time"
+	"os"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
-	conn, err := grpc.NewClient("localhost:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "YAML config file")
+	idleTimeout := flag.Duration("idle-timeout", 0, "close player connections silent for this long, 0 disables (gateway.idle_timeout)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "idle-timeout" {
+			cfg.Gateway.IdleTimeout = *idleTimeout
+		}
+	})
+
+	conn, err := grpc.NewClient(cfg.Gateway.EngineAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		log.Fatalf("did not connect to engine: %v", err)
 	}
 	defer conn.Close()
 
-	wsHandler := handler.NewGatewayHandler(conn)
+	wsHandler := handler.NewGatewayHandler(conn, handler.Options{
+		IdleTimeout:    cfg.Gateway.IdleTimeout,
+		AllowedOrigins: cfg.Gateway.AllowedOrigins,
+	})
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ws", wsHandler.ServeHTTP)
+	mux.HandleFunc("/ws/spectate", wsHandler.ServeSpectate)
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte("OK"))
 		if err != nil {
@@ -29,13 +49,13 @@ func main() {
 	})
 
 	srv := &http.Server{
-		Addr:         ":8081",
+		Addr:         cfg.Gateway.Listen,
 		Handler:      mux,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+		ReadTimeout:  cfg.Gateway.ReadTimeout,
+		WriteTimeout: cfg.Gateway.WriteTimeout,
 	}
 
-	log.Println("Gateway listening on :8081...")
+	log.Printf("Gateway listening on %s...", cfg.Gateway.Listen)
 	if err := srv.ListenAndServe(); err != nil {
 		log.Printf("failed to start gateway: %v", err)
 		return
@@ -2,7 +2,9 @@ package handler
 
 import (
 	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/backoff"
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -11,25 +13,41 @@ import (
 	"github.com/coder/websocket"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
+// Options configures a GatewayHandler.
+type Options struct {
+	// IdleTimeout disconnects players that send nothing for that long. Zero
+	// disables the timeout.
+	IdleTimeout time.Duration
+	// AllowedOrigins lists the host patterns browsers may connect from.
+	AllowedOrigins []string
+}
+
 type GatewayHandler struct {
 	grpcClient pb.GameServiceClient
+	opts       Options
 }
 
-func NewGatewayHandler(conn *grpc.ClientConn) *GatewayHandler {
+func NewGatewayHandler(conn *grpc.ClientConn, opts Options) *GatewayHandler {
 	return &GatewayHandler{
 		grpcClient: pb.NewGameServiceClient(conn),
+		opts:       opts,
 	}
 }
 
-func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-		InsecureSkipVerify: true,
-		OriginPatterns:     []string{"*"},
-		CompressionMode:    websocket.CompressionDisabled,
+func (h *GatewayHandler) accept(w http.ResponseWriter, r *http.Request) (*websocket.Conn, error) {
+	return websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns:  h.opts.AllowedOrigins,
+		CompressionMode: websocket.CompressionDisabled,
 	})
+}
+
+func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c, err := h.accept(w, r)
 	if err != nil {
 		log.Printf("failed to accept websocket: %v", err)
 		return
@@ -38,15 +56,147 @@ func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 
-	stream, err := h.grpcClient.Play(ctx)
-	if err != nil {
-		log.Printf("failed to connect to game engine: %v", err)
-		c.Close(websocket.StatusBadGateway, "game engine unavailable")
+	s := &session{
+		conn:        c,
+		incoming:    make(chan *pb.ClientMessage),
+		idleTimeout: h.opts.IdleTimeout,
+	}
+	go s.readClient(ctx)
+
+	join, ok := <-s.incoming
+	if !ok {
 		return
 	}
 
+	// A client that reconnected resumes its session, so the gateway can
+	// keep resuming it if the engine stream drops before sending a new token.
+	s.resumeToken = join.GetJoin().GetResumeToken()
+
+	b := backoff.New()
+	for {
+		err := s.relay(ctx, h.grpcClient, join)
+		if err == nil || errors.Is(err, errClientClosed) || ctx.Err() != nil {
+			break
+		}
+		if s.resumeToken == "" && status.Code(err) == codes.Unavailable {
+			// Nothing was joined yet, so the client can simply join again
+			// once the engine is back.
+			log.Printf("engine unavailable: %v", err)
+			c.Close(websocket.StatusTryAgainLater, closeReason(err))
+			return
+		}
+		if s.resumeToken == "" || !backoff.Retryable(err) {
+			log.Printf("session closed: %v", err)
+			// This close status tells the client not to reconnect.
+			c.Close(websocket.StatusPolicyViolation, closeReason(err))
+			return
+		}
+
+		if s.relayed {
+			b.Reset()
+		}
+		log.Printf("engine stream dropped, resuming session: %v", err)
+		join = &pb.ClientMessage{
+			Payload: &pb.ClientMessage_Join{
+				Join: &pb.JoinRequest{ResumeToken: s.resumeToken},
+			},
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(b.Next()):
+		}
+	}
+
+	c.Close(websocket.StatusNormalClosure, "bye")
+}
+
+var errClientClosed = errors.New("client closed the connection")
+
+// maxCloseReason is the longest reason a websocket close frame can carry.
+const maxCloseReason = 123
+
+func closeReason(err error) string {
+	reason := status.Convert(err).Message()
+	if len(reason) > maxCloseReason {
+		reason = reason[:maxCloseReason]
+	}
+	return reason
+}
+
+// session connects one websocket client to the engine. The engine stream can
+// be replaced underneath it when the session is resumed.
+type session struct {
+	conn        *websocket.Conn
+	incoming    chan *pb.ClientMessage
+	idleTimeout time.Duration
+
+	clientErr   error
+	resumeToken string
+	relayed     bool
+}
+
+// readClient forwards websocket messages to incoming until the client goes
+// away.
+func (s *session) readClient(ctx context.Context) {
+	defer close(s.incoming)
+
+	for {
+		msgType, data, err := s.read(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				log.Printf("client idle for %v, closing", s.idleTimeout)
+			}
+			if websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+				s.clientErr = err
+			}
+			return
+		}
+
+		if msgType != websocket.MessageBinary {
+			log.Printf("unexpected message type: %v", msgType)
+			continue
+		}
+
+		var clientMsg pb.ClientMessage
+		if err := proto.Unmarshal(data, &clientMsg); err != nil {
+			log.Printf("failed to unmarshal client message: %v", err)
+			continue
+		}
+
+		select {
+		case s.incoming <- &clientMsg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// read reads the next client message, giving up after the idle timeout.
+func (s *session) read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	if s.idleTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.idleTimeout)
+		defer cancel()
+	}
+	return s.conn.Read(ctx)
+}
+
+// relay opens an engine stream with join and pipes messages both ways until
+// either side closes.
+func (s *session) relay(ctx context.Context, client pb.GameServiceClient, join *pb.ClientMessage) error {
+	s.relayed = false
+
 	g, ctx := errgroup.WithContext(ctx)
 
+	stream, err := client.Play(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(join); err != nil {
+		return err
+	}
+
 	g.Go(func() error {
 		for {
 			msg, err := stream.Recv()
@@ -56,6 +206,11 @@ func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				}
 				return err
 			}
+			s.relayed = true
+
+			if info := msg.GetSession(); info != nil {
+				s.resumeToken = info.ResumeToken
+			}
 
 			data, err := proto.Marshal(msg)
 			if err != nil {
@@ -64,43 +219,87 @@ func (h *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 
 			writeCtx, cancel := context.WithTimeout(ctx, time.Second*5)
-			err = c.Write(writeCtx, websocket.MessageBinary, data)
+			err = s.conn.Write(writeCtx, websocket.MessageBinary, data)
 			cancel()
 			if err != nil {
-				return err
+				return errClientClosed
 			}
 		}
 	})
 
 	g.Go(func() error {
 		for {
-			msgType, data, err := c.Read(ctx)
-			if err != nil {
-				if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
-					return nil
+			var clientMsg *pb.ClientMessage
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case msg, ok := <-s.incoming:
+				if !ok {
+					if s.clientErr != nil {
+						return errClientClosed
+					}
+					return stream.CloseSend()
 				}
-				return err
+				clientMsg = msg
 			}
 
-			if msgType != websocket.MessageBinary {
-				log.Printf("unexpected message type: %v", msgType)
-				continue
+			if err := stream.Send(clientMsg); err != nil {
+				return err
 			}
+		}
+	})
 
-			var clientMsg pb.ClientMessage
-			if err := proto.Unmarshal(data, &clientMsg); err != nil {
-				log.Printf("failed to unmarshal client message: %v", err)
-				continue
-			}
+	return g.Wait()
+}
 
-			if err := stream.Send(&clientMsg); err != nil {
-				return err
+// ServeSpectate streams a running match to a websocket spectator. The match
+// is chosen with the match_id query parameter.
+func (h *GatewayHandler) ServeSpectate(w http.ResponseWriter, r *http.Request) {
+	matchID := r.URL.Query().Get("match_id")
+	if matchID == "" {
+		http.Error(w, "match_id is required", http.StatusBadRequest)
+		return
+	}
+
+	c, err := h.accept(w, r)
+	if err != nil {
+		log.Printf("failed to accept websocket: %v", err)
+		return
+	}
+	defer c.Close(websocket.StatusInternalError, "internal error")
+
+	// Spectators only listen; CloseRead cancels ctx once the client goes away.
+	ctx := c.CloseRead(r.Context())
+
+	stream, err := h.grpcClient.Spectate(ctx, &pb.SpectateRequest{MatchId: matchID})
+	if err != nil {
+		log.Printf("failed to connect to game engine: %v", err)
+		c.Close(websocket.StatusBadGateway, "game engine unavailable")
+		return
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				log.Printf("spectator session closed: %v", err)
 			}
+			break
 		}
-	})
 
-	if err := g.Wait(); err != nil {
-		log.Printf("session closed: %v", err)
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			log.Printf("failed to marshal message: %v", err)
+			continue
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, time.Second*5)
+		err = c.Write(writeCtx, websocket.MessageBinary, data)
+		cancel()
+		if err != nil {
+			log.Printf("spectator session closed: %v", err)
+			break
+		}
 	}
 
 	c.Close(websocket.StatusNormalClosure, "bye")
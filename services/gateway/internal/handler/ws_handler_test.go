@@ -0,0 +1,109 @@
+package handler
+
+import (
+	pb "GoTetrisOnline/api/proto/game/v1"
+	"context"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// busyEngine refuses the first resume like an engine that has not noticed
+// the previous stream dropping yet, then accepts it.
+type busyEngine struct {
+	pb.UnimplementedGameServiceServer
+
+	attempts atomic.Int32
+}
+
+func (e *busyEngine) Play(stream grpc.BidiStreamingServer[pb.ClientMessage, pb.ServerMessage]) error {
+	msg, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if token := msg.GetJoin().GetResumeToken(); token != "token-1" {
+		return status.Errorf(codes.NotFound, "unexpected resume token %q", token)
+	}
+
+	if e.attempts.Add(1) == 1 {
+		return status.Error(codes.FailedPrecondition, "session is still attached to another stream")
+	}
+	return stream.Send(&pb.ServerMessage{
+		Payload: &pb.ServerMessage_Session{Session: &pb.SessionInfo{ResumeToken: "token-1"}},
+	})
+}
+
+func newTestGateway(t *testing.T, engine pb.GameServiceServer) *httptest.Server {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer()
+	pb.RegisterGameServiceServer(srv, engine)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///engine",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	gateway := httptest.NewServer(NewGatewayHandler(conn, Options{}))
+	t.Cleanup(gateway.Close)
+	return gateway
+}
+
+func TestGateway_RetriesResumeOfAttachedSession(t *testing.T) {
+	engine := &busyEngine{}
+	gateway := newTestGateway(t, engine)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(gateway.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer c.CloseNow()
+
+	data, err := proto.Marshal(&pb.ClientMessage{
+		Payload: &pb.ClientMessage_Join{Join: &pb.JoinRequest{ResumeToken: "token-1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, websocket.MessageBinary, data); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	_, data, err = c.Read(ctx)
+	if err != nil {
+		t.Fatalf("expected the resumed session, got %v", err)
+	}
+	var msg pb.ServerMessage
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.GetSession().GetResumeToken() != "token-1" {
+		t.Errorf("expected session info, got %v", &msg)
+	}
+	if n := engine.attempts.Load(); n != 2 {
+		t.Errorf("expected 2 resume attempts, got %d", n)
+	}
+}
@@ -2,40 +2,67 @@ package main
 
 import (
 	pb "GoTetrisOnline/api/proto/game/v1"
+	"GoTetrisOnline/pkg/backoff"
 	"GoTetrisOnline/pkg/core"
+	"GoTetrisOnline/pkg/latency"
+	"GoTetrisOnline/pkg/predict"
 	"GoTetrisOnline/pkg/renderer"
 	"context"
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall/js"
 	"time"
 
 	"github.com/coder/websocket"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"google.golang.org/protobuf/proto"
 )
 
 const (
-	wsURL    = "ws://localhost:8081/ws"
-	cellSize = 20
-	boardX   = 20
-	boardY   = 20
-	sidebarX = boardX + core.BoardWidth*cellSize + 40
-	screenW  = 640
-	screenH  = 480
+	defaultServer = "ws://localhost:8081/ws"
+	defaultMatch  = "room-1"
+
+	cellSize   = 20
+	boardX     = 20
+	boardY     = 20
+	meterWidth = 6
+	sidebarX   = boardX + core.BoardWidth*cellSize + 40
+	screenW    = 640
+	screenH    = 480
+
+	popupDuration = 1500 * time.Millisecond
 )
 
+// Game is shared by the Ebiten loop and the connection goroutine. mu guards
+// the connection, the displayed state and everything shown around it.
 type Game struct {
 	conn          *websocket.Conn
+	mu            sync.Mutex
+	predictor     *predict.Predictor
+	pinger        *latency.Pinger
+	authState     *pb.StateUpdate
 	state         *pb.StateUpdate
+	timer         renderer.Timer
 	connected     bool
 	err           error
 	lastInput     time.Time
 	inputCooldown time.Duration
 	ctx           context.Context
 	cancel        context.CancelFunc
+	resumeToken   string
+	popup         string
+	popupUntil    time.Time
+	result        string
 }
 
 func (g *Game) Update() error {
@@ -43,11 +70,26 @@ func (g *Game) Update() error {
 		return fmt.Errorf("quit")
 	}
 
-	if g.conn == nil || g.state == nil {
+	g.mu.Lock()
+	ready := g.connected && g.state != nil
+	paused := ready && g.state.Paused
+	g.mu.Unlock()
+	if !ready {
 		return nil
 	}
 
-	if time.Since(g.lastInput) < g.inputCooldown {
+	// Pausing is not predicted, and the server ignores moves while the game
+	// is paused.
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.send(&pb.ClientMessage{
+			Payload: &pb.ClientMessage_Input{
+				Input: &pb.InputRequest{Input: pb.InputType_INPUT_PAUSE},
+			},
+		})
+		return nil
+	}
+
+	if paused || time.Since(g.lastInput) < g.inputCooldown {
 		return nil
 	}
 
@@ -72,54 +114,131 @@ func (g *Game) Update() error {
 	} else if ebiten.IsKeyPressed(ebiten.KeySpace) {
 		input = pb.InputType_INPUT_HARD_DROP
 		sendInput = true
+	} else if ebiten.IsKeyPressed(ebiten.KeyC) || ebiten.IsKeyPressed(ebiten.KeyShiftLeft) {
+		input = pb.InputType_INPUT_HOLD
+		sendInput = true
+	} else if ebiten.IsKeyPressed(ebiten.KeyZ) {
+		input = pb.InputType_INPUT_UNDO
+		sendInput = true
 	}
 
 	if sendInput {
 		g.lastInput = time.Now()
-		msg := &pb.ClientMessage{
+
+		g.mu.Lock()
+		seq := g.predictor.Input(input)
+		g.state = g.predictor.State()
+		g.mu.Unlock()
+
+		g.send(&pb.ClientMessage{
 			Payload: &pb.ClientMessage_Input{
-				Input: &pb.InputRequest{Input: input},
+				Input: &pb.InputRequest{SequenceId: seq, Input: input},
 			},
-		}
-		data, err := proto.Marshal(msg)
-		if err != nil {
-			log.Printf("Marshal error: %v", err)
-			return nil
-		}
+		})
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
+	return nil
+}
 
-		if err := g.conn.Write(ctx, websocket.MessageBinary, data); err != nil {
-			log.Printf("Write error: %v", err)
-			return nil
-		}
+// send writes msg to the gateway. Failures are only logged; a dropped
+// connection is picked up by readLoop.
+func (g *Game) send(msg *pb.ClientMessage) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		log.Printf("Marshal error: %v", err)
+		return
 	}
 
-	return nil
+	g.mu.Lock()
+	conn := g.conn
+	g.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := conn.Write(ctx, websocket.MessageBinary, data); err != nil {
+		log.Printf("Write error: %v", err)
+	}
+}
+
+// setState stores an authoritative state from the server and shows it with
+// the pending inputs predicted on top.
+func (g *Game) setState(state *pb.StateUpdate) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.authState = state
+	g.predictor.Reconcile(state)
+	g.state = g.predictor.State()
+	g.timer.Update(state.TickId, time.Now())
+	g.timer.SetPaused(state.Paused)
+}
+
+// showPopup shows text below the board for a moment.
+func (g *Game) showPopup(text string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.popup = text
+	g.popupUntil = time.Now().Add(popupDuration)
+}
+
+// finish shows the end of the game and stops the timer.
+func (g *Game) finish(result string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.result = result
+	g.timer.Stop()
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
 	screen.Fill(color.RGBA{20, 20, 20, 255})
 
-	if g.err != nil {
-		ebitenutil.DebugPrint(screen, fmt.Sprintf("Error: %v", g.err))
+	g.mu.Lock()
+	err, connected, resuming := g.err, g.connected, g.resumeToken != ""
+	result, popup := g.result, ""
+	if time.Now().Before(g.popupUntil) {
+		popup = g.popup
+	}
+	var view *renderer.GameView
+	if g.state != nil {
+		view = renderer.StateToView(g.state)
+		view.Elapsed = g.timer.Elapsed(time.Now())
+	}
+	g.mu.Unlock()
+
+	if err != nil {
+		ebitenutil.DebugPrint(screen, fmt.Sprintf("Error: %v", err))
 		return
 	}
 
-	if !g.connected {
-		ebitenutil.DebugPrint(screen, "Connecting...")
+	if !connected {
+		if resuming {
+			ebitenutil.DebugPrint(screen, "Connection lost, reconnecting...")
+		} else {
+			ebitenutil.DebugPrint(screen, "Connecting...")
+		}
 		return
 	}
 
-	if g.state == nil {
-		ebitenutil.DebugPrint(screen, "Waiting for game state...")
+	if view == nil {
+		ebitenutil.DebugPrint(screen, "Waiting for other players...")
 		return
 	}
 
-	view := renderer.StateToView(g.state)
 	g.drawBoard(screen, view)
+	g.drawGarbageMeter(screen, view)
 	g.drawSidebar(screen, view)
+
+	switch {
+	case result != "":
+		ebitenutil.DebugPrintAt(screen, result, boardX, boardY+view.Height*cellSize+10)
+	case popup != "":
+		ebitenutil.DebugPrintAt(screen, popup, boardX, boardY+view.Height*cellSize+10)
+	case view.Paused:
+		ebitenutil.DebugPrintAt(screen, "PAUSED - press P to resume", boardX, boardY+view.Height*cellSize+10)
+	}
 }
 
 func (g *Game) drawBoard(screen *ebiten.Image, view *renderer.GameView) {
@@ -131,6 +250,8 @@ func (g *Game) drawBoard(screen *ebiten.Image, view *renderer.GameView) {
 			switch cell.Type {
 			case renderer.CellPiece, renderer.CellFixed:
 				clr = renderer.GetPieceColor(cell.PieceType)
+			case renderer.CellGhost:
+				clr = renderer.GetGhostColor(cell.PieceType)
 			default:
 				clr = color.RGBA{40, 40, 40, 255}
 			}
@@ -142,31 +263,48 @@ func (g *Game) drawBoard(screen *ebiten.Image, view *renderer.GameView) {
 	}
 }
 
+func (g *Game) drawGarbageMeter(screen *ebiten.Image, view *renderer.GameView) {
+	rows := min(int(view.Garbage), view.Height)
+	if rows <= 0 {
+		return
+	}
+
+	px := float32(boardX - meterWidth - 2)
+	py := float32(boardY + (view.Height-rows)*cellSize)
+	vector.DrawFilledRect(screen, px, py, meterWidth, float32(rows*cellSize-1), color.RGBA{255, 40, 40, 255}, false)
+}
+
 func (g *Game) drawSidebar(screen *ebiten.Image, view *renderer.GameView) {
 	y := boardY
 	ebitenutil.DebugPrintAt(screen, "NEXT:", sidebarX, y)
 	y += 30
 
 	if view.NextPiece != core.PieceNone {
-		nextGrid := renderer.RenderNextPieceGrid(view.NextPiece)
-		clr := renderer.GetPieceColor(view.NextPiece)
-
-		for py := 0; py < nextGrid.Size; py++ {
-			for px := 0; px < nextGrid.Size; px++ {
-				if nextGrid.Grid[py][px] {
-					fx := float32(sidebarX + px*cellSize)
-					fy := float32(y + py*cellSize)
-					vector.DrawFilledRect(screen, fx, fy, cellSize-1, cellSize-1, clr, false)
-				}
-			}
-		}
-		y += nextGrid.Size*cellSize + 30
+		y = g.drawPiecePreview(screen, view.NextPiece, y)
+	}
+
+	ebitenutil.DebugPrintAt(screen, "HOLD:", sidebarX, y)
+	y += 30
+
+	if view.HeldPiece != core.PieceNone {
+		y = g.drawPiecePreview(screen, view.HeldPiece, y)
 	}
 
 	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Score: %d", view.Score), sidebarX, y)
 	y += 20
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Level: %d", view.Level), sidebarX, y)
-	y += 40
+	if view.ShowTimer() {
+		for _, line := range view.TimerLines() {
+			ebitenutil.DebugPrintAt(screen, line, sidebarX, y)
+			y += 20
+		}
+	} else {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Level: %d", view.Level), sidebarX, y)
+		y += 20
+	}
+	if rtt := g.pinger.RTT(); rtt > 0 {
+		ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Ping: %d ms", rtt.Milliseconds()), sidebarX, y)
+	}
+	y += 20
 	ebitenutil.DebugPrintAt(screen, "CONTROLS:", sidebarX, y)
 	y += 20
 	ebitenutil.DebugPrintAt(screen, "A/←: Left", sidebarX, y)
@@ -177,16 +315,45 @@ func (g *Game) drawSidebar(screen *ebiten.Image, view *renderer.GameView) {
 	y += 15
 	ebitenutil.DebugPrintAt(screen, "Space: Drop", sidebarX, y)
 	y += 15
+	ebitenutil.DebugPrintAt(screen, "C: Hold", sidebarX, y)
+	y += 15
+	ebitenutil.DebugPrintAt(screen, "P: Pause", sidebarX, y)
+	y += 15
+	if view.Mode == renderer.ModeZen {
+		ebitenutil.DebugPrintAt(screen, "Z: Undo", sidebarX, y)
+		y += 15
+	}
 	ebitenutil.DebugPrintAt(screen, "Q: Quit", sidebarX, y)
 }
 
+func (g *Game) drawPiecePreview(screen *ebiten.Image, t core.PieceType, y int) int {
+	grid := renderer.RenderNextPieceGrid(t)
+	clr := renderer.GetPieceColor(t)
+
+	for py := 0; py < grid.Size; py++ {
+		for px := 0; px < grid.Size; px++ {
+			if grid.Grid[py][px] {
+				fx := float32(sidebarX + px*cellSize)
+				fy := float32(y + py*cellSize)
+				vector.DrawFilledRect(screen, fx, fy, cellSize-1, cellSize-1, clr, false)
+			}
+		}
+	}
+	return y + grid.Size*cellSize + 30
+}
+
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return screenW, screenH
 }
 
 func main() {
-	g := &Game{inputCooldown: 150 * time.Millisecond}
-	go g.connectToGateway()
+	g := &Game{
+		inputCooldown: 150 * time.Millisecond,
+		// Prediction is on unless the page is opened with ?predict=0.
+		predictor: predict.New(queryParam("predict") != "0"),
+		pinger:    latency.NewPinger(),
+	}
+	go g.run()
 
 	ebiten.SetWindowSize(screenW, screenH)
 	ebiten.SetWindowTitle("Tetris WASM Client")
@@ -195,33 +362,144 @@ func main() {
 	}
 }
 
-func (g *Game) connectToGateway() {
+// run keeps the client connected to the gateway, resuming the session with
+// backoff when the connection drops.
+func (g *Game) run() {
+	b := backoff.New()
+	mode := queryParam("mode")
+	goalLines, _ := strconv.ParseInt(queryParam("lines"), 10, 32)
+
+	// Single player modes get a private match unless the page names one.
+	matchID := queryParamOr("match", defaultMatch)
+	if mode != "" && mode != "marathon" && queryParam("match") == "" {
+		matchID = fmt.Sprintf("%s-%08x", mode, rand.Uint32())
+	}
+
+	join := &pb.JoinRequest{
+		MatchId:     matchID,
+		Token:       queryParam("token"),
+		Name:        queryParam("name"),
+		Mode:        mode,
+		CleanCheese: queryParam("clean") == "1",
+	}
+	if mode == "dig" {
+		join.DigLines = int32(goalLines)
+	} else {
+		join.SprintLines = int32(goalLines)
+	}
+
+	for {
+		start := time.Now()
+		err := g.connectToGateway(join)
+
+		g.mu.Lock()
+		g.connected = false
+		resumeToken := g.resumeToken
+		final := err != nil && (!reconnectable(err, resumeToken != "") || g.result != "")
+		if final {
+			g.err = err
+		}
+		g.mu.Unlock()
+
+		if err == nil || final {
+			return
+		}
+
+		if time.Since(start) > backoff.DefaultMax {
+			b.Reset()
+		}
+		log.Printf("Connection lost, reconnecting: %v", err)
+		time.Sleep(b.Next())
+
+		if resumeToken != "" {
+			join = &pb.JoinRequest{ResumeToken: resumeToken}
+		}
+	}
+}
+
+// reconnectable reports whether connecting again can help after err. A
+// dropped connection can be resumed once the server handed out a token. The
+// gateway closes with an explicit status when it gave up on the session, and
+// only asks to try again when nothing was joined yet.
+func reconnectable(err error, resuming bool) bool {
+	var closeErr websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		return resuming
+	}
+
+	switch closeErr.Code {
+	case websocket.StatusTryAgainLater, websocket.StatusGoingAway:
+		return true
+	}
+	return false
+}
+
+func (g *Game) connectToGateway(join *pb.JoinRequest) error {
 	dialCtx, dialCancel := context.WithTimeout(context.Background(), time.Minute)
 	defer dialCancel()
 
-	c, _, err := websocket.Dial(dialCtx, wsURL, nil)
+	c, _, err := websocket.Dial(dialCtx, queryParamOr("server", defaultServer), nil)
 	if err != nil {
-		g.err = err
 		log.Printf("Connection error: %v", err)
-		return
+		return err
 	}
 
+	g.mu.Lock()
 	g.conn = c
 	g.connected = true
+	g.mu.Unlock()
 	g.ctx, g.cancel = context.WithCancel(context.Background())
 
 	joinMsg := &pb.ClientMessage{
-		Payload: &pb.ClientMessage_Join{
-			Join: &pb.JoinRequest{MatchId: "room-1", Token: "token"},
-		},
+		Payload: &pb.ClientMessage_Join{Join: join},
 	}
 	data, _ := proto.Marshal(joinMsg)
 	_ = c.Write(g.ctx, websocket.MessageBinary, data)
 
-	go g.readLoop()
+	go g.pingLoop(g.ctx)
+
+	return g.readLoop()
 }
 
-func (g *Game) readLoop() {
+// pingLoop pings the server until ctx is done, keeping the connection alive
+// and measuring latency.
+func (g *Game) pingLoop(ctx context.Context) {
+	ticker := time.NewTicker(latency.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.send(&pb.ClientMessage{
+				Payload: &pb.ClientMessage_Ping{Ping: g.pinger.Ping()},
+			})
+		}
+	}
+}
+
+// queryParam reads a parameter from the query string of the page hosting
+// the client.
+func queryParam(name string) string {
+	search := js.Global().Get("location").Get("search").String()
+	values, err := url.ParseQuery(strings.TrimPrefix(search, "?"))
+	if err != nil {
+		return ""
+	}
+	return values.Get(name)
+}
+
+func queryParamOr(name, fallback string) string {
+	if value := queryParam(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// readLoop handles server messages until the connection closes. It returns
+// nil on a normal closure.
+func (g *Game) readLoop() error {
 	defer func() {
 		if g.cancel != nil {
 			g.cancel()
@@ -234,7 +512,7 @@ func (g *Game) readLoop() {
 	for {
 		select {
 		case <-g.ctx.Done():
-			return
+			return nil
 		default:
 		}
 
@@ -242,12 +520,10 @@ func (g *Game) readLoop() {
 		if err != nil {
 			if websocket.CloseStatus(err) == websocket.StatusNormalClosure {
 				log.Println("Connection closed normally")
-				return
+				return nil
 			}
 			log.Printf("Read error: %v", err)
-			g.err = err
-			g.connected = false
-			return
+			return err
 		}
 
 		var msg pb.ServerMessage
@@ -257,11 +533,38 @@ func (g *Game) readLoop() {
 		}
 
 		switch payload := msg.Payload.(type) {
+		case *pb.ServerMessage_Session:
+			g.mu.Lock()
+			g.resumeToken = payload.Session.ResumeToken
+			g.mu.Unlock()
 		case *pb.ServerMessage_State:
-			g.state = payload.State
+			g.setState(payload.State)
+		case *pb.ServerMessage_Pong:
+			g.pinger.Pong(payload.Pong)
+		case *pb.ServerMessage_Delta:
+			state, err := renderer.ApplyDelta(g.authState, payload.Delta)
+			if err != nil {
+				log.Printf("Requesting resync: %v", err)
+				g.send(&pb.ClientMessage{
+					Payload: &pb.ClientMessage_Resync{Resync: &pb.ResyncRequest{}},
+				})
+				continue
+			}
+			g.setState(state)
 		case *pb.ServerMessage_Event:
-			if payload.Event.Type == pb.EventType_EVENT_GAME_OVER {
+			switch payload.Event.Type {
+			case pb.EventType_EVENT_GAME_OVER:
 				log.Println("Game Over!")
+				g.finish("GAME OVER")
+			case pb.EventType_EVENT_WINNER:
+				log.Println("You win!")
+				g.finish("YOU WIN!")
+			case pb.EventType_EVENT_RESULT:
+				log.Printf("Finished: %s", payload.Event.Message)
+				g.finish(renderer.ResultTitle(payload.Event) + "  " + strings.Join(renderer.ResultLines(payload.Event), "  "))
+			case pb.EventType_EVENT_COMBO, pb.EventType_EVENT_BACK_TO_BACK, pb.EventType_EVENT_PERFECT_CLEAR,
+				pb.EventType_EVENT_PAUSED, pb.EventType_EVENT_RESUMED, pb.EventType_EVENT_PAUSE_REQUESTED:
+				g.showPopup(payload.Event.Message)
 			}
 		}
 	}